@@ -0,0 +1,335 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Sandbox API capabilities
+
+	analyzer.go - submit object, wait for analysis and fetch results in one call
+*/
+
+package vone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// ErrAnalysisTimeout - analysis was not finished within Analyzer.Timeout
+var ErrAnalysisTimeout = errors.New("analysis timeout")
+
+// maxDownloadAttempts - how many times Analyzer requests report or investigation
+// package that is not available yet
+const maxDownloadAttempts = 10
+
+// AnalysisStage - step of Analyzer workflow
+type AnalysisStage string
+
+const (
+	StageSubmitted            AnalysisStage = "submitted"
+	StageRunning              AnalysisStage = "running"
+	StageAnalyzed             AnalysisStage = "analyzed"
+	StageSuspiciousObjects    AnalysisStage = "suspiciousObjects"
+	StageReport               AnalysisStage = "report"
+	StageInvestigationPackage AnalysisStage = "investigationPackage"
+)
+
+// AnalysisProgress - event passed to Analyzer.OnProgress
+type AnalysisProgress struct {
+	Object  string        // File path or URL
+	ID      string        // Submission ID
+	Stage   AnalysisStage // Finished step
+	Polls   int           // Amount of submission status requests made so far
+	Elapsed time.Duration // Time since submission started
+}
+
+// Analysis - combined outcome of sandbox analysis
+type Analysis struct {
+	Object                   string                              // File path or URL
+	ID                       string                              // Submission ID
+	Quota                    *SandboxSubmitFileResponseHeaders   // Submission quota after submission
+	OperationLocation        string                              // URL of submission status
+	Digest                   Digest                              // Hashes of uploaded file verified against Vision One response
+	Status                   *SandboxSubmissionStatusResponse    // Final submission status
+	Result                   *SandboxAnalysisResultsResponseItem // Analysis results
+	SuspiciousObjects        []SandboxSuspiciousObject           // Suspicious objects for risky object
+	ReportPath               string                              // Path of downloaded PDF report
+	InvestigationPackagePath string                              // Path of downloaded investigation package
+	Duration                 time.Duration                       // Time from submission till the end of analysis
+	FromCache                bool                                // Result was taken from cache without submission
+}
+
+// Risky - return whether object has any risk level
+func (a *Analysis) Risky() bool {
+	return a.Result != nil && a.Result.RiskLevel != RiskLevelNoRisk
+}
+
+// Analyzer - submit files and URLs to sandbox, wait for analysis to finish and
+// fetch analysis results, suspicious objects and optionally PDF report and
+// investigation package. Usage:
+//
+//	analyzer := vone.NewAnalyzer(v1)
+//	analysis, err := analyzer.AnalyzeFile(ctx, "sample.exe")
+type Analyzer struct {
+	// PollInterval - delay between the first and the second submission status requests
+	PollInterval time.Duration
+	// MaxPollInterval - upper limit of delay between submission status requests
+	MaxPollInterval time.Duration
+	// PollMultiplier - growth factor of delay between submission status requests
+	PollMultiplier float64
+	// Timeout - limit for the whole workflow including downloads. Zero means no limit
+	Timeout time.Duration
+	// DownloadFolder - folder to store PDF reports and investigation packages to
+	DownloadFolder string
+	// DownloadReport - download PDF report for risky objects
+	DownloadReport bool
+	// DownloadInvestigationPackage - download investigation package for risky objects
+	DownloadInvestigationPackage bool
+	// ArchivePassword - if not empty, password sandbox uses to extract submitted archives
+	ArchivePassword string
+	// DocumentPassword - if not empty, password sandbox uses to open submitted documents
+	DocumentPassword string
+	// OnProgress - if not nil, called after each step
+	OnProgress func(progress AnalysisProgress)
+
+	vone *VOne
+}
+
+// NewAnalyzer - create analyzer polling status from each 5 seconds up to
+// each minute with 10 minutes timeout and without downloads
+func NewAnalyzer(v1 *VOne) *Analyzer {
+	return &Analyzer{
+		PollInterval:    5 * time.Second,
+		MaxPollInterval: time.Minute,
+		PollMultiplier:  1.5,
+		Timeout:         10 * time.Minute,
+		vone:            v1,
+	}
+}
+
+// AnalyzeFile - submit file and wait for its analysis results. On error
+// return steps finished so far along with the error
+func (a *Analyzer) AnalyzeFile(ctx context.Context, filePath string) (*Analysis, error) {
+	return a.analyze(ctx, filePath, func(ctx context.Context, analysis *Analysis) error {
+		submit := a.vone.SandboxSubmitFile()
+		if err := submit.SetFilePath(ctx, filePath); err != nil {
+			return err
+		}
+		if a.ArchivePassword != "" {
+			submit.SetArchivePassword(a.ArchivePassword)
+		}
+		if a.DocumentPassword != "" {
+			submit.SetDocumentPassword(a.DocumentPassword)
+		}
+		response, headers, err := submit.Do(ctx)
+		if response != nil {
+			// submitted even if digest does not match
+			analysis.ID = response.ID
+			analysis.Quota = headers
+			analysis.OperationLocation = headers.OperationLocation
+		}
+		if err != nil {
+			return err
+		}
+		analysis.Digest = submit.UploadedDigest()
+		return nil
+	})
+}
+
+// AnalyzeURL - submit URL and wait for its analysis results. On error
+// return steps finished so far along with the error
+func (a *Analyzer) AnalyzeURL(ctx context.Context, url string) (*Analysis, error) {
+	return a.analyze(ctx, url, func(ctx context.Context, analysis *Analysis) error {
+		response, headers, err := a.vone.SandboxSubmitURLs().AddURL(url).Do(ctx)
+		if err != nil {
+			return err
+		}
+		if len(response) != 1 {
+			return fmt.Errorf("wrong response length: %d", len(response))
+		}
+		if err := response[0].GetError(); err != nil {
+			return err
+		}
+		analysis.ID = response[0].Body.ID
+		analysis.Quota = headers
+		analysis.OperationLocation = response[0].OperationLocation()
+		return nil
+	})
+}
+
+// AnalyzeSubmission - wait for analysis results of object that is already
+// submitted with given submission ID
+func (a *Analyzer) AnalyzeSubmission(ctx context.Context, object string, id string) (*Analysis, error) {
+	return a.AnalyzeOperation(ctx, object, id, "")
+}
+
+// AnalyzeOperation - wait for analysis results of object that is already
+// submitted following Operation-Location URL returned on submission
+func (a *Analyzer) AnalyzeOperation(ctx context.Context, object string, id string, operationLocation string) (*Analysis, error) {
+	return a.analyze(ctx, object, func(ctx context.Context, analysis *Analysis) error {
+		analysis.ID = id
+		analysis.OperationLocation = operationLocation
+		return nil
+	})
+}
+
+// submitFunc - submit object and set submission ID, quota and, for files, digest of analysis
+type submitFunc func(ctx context.Context, analysis *Analysis) error
+
+func (a *Analyzer) analyze(ctx context.Context, object string, submit submitFunc) (*Analysis, error) {
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, a.Timeout, ErrAnalysisTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	analysis := &Analysis{Object: object}
+	progress := func(stage AnalysisStage, polls int) {
+		if a.OnProgress == nil {
+			return
+		}
+		a.OnProgress(AnalysisProgress{
+			Object:  object,
+			ID:      analysis.ID,
+			Stage:   stage,
+			Polls:   polls,
+			Elapsed: time.Since(start),
+		})
+	}
+	fail := func(err error) (*Analysis, error) {
+		if ctx.Err() != nil {
+			err = context.Cause(ctx)
+		}
+		if analysis.ID == "" {
+			return analysis, fmt.Errorf("%s: %w", object, err)
+		}
+		return analysis, fmt.Errorf("%s: %s: %w", object, analysis.ID, err)
+	}
+
+	if err := submit(ctx, analysis); err != nil {
+		return fail(err)
+	}
+	id := analysis.ID
+	progress(StageSubmitted, 0)
+
+	status, err := a.wait(ctx, id, analysis.OperationLocation, progress)
+	analysis.Status = status
+	analysis.Duration = time.Since(start)
+	if err != nil {
+		return fail(err)
+	}
+	if analysis.Digest != (Digest{}) {
+		if err := analysis.Digest.Verify(status.Digest); err != nil {
+			return fail(err)
+		}
+	}
+	result, err := a.vone.SandboxAnalysisResults(id).Do(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	analysis.Result = result
+	progress(StageAnalyzed, 0)
+	if !analysis.Risky() {
+		return analysis, nil
+	}
+
+	suspiciousObjects, err := a.vone.SandboxSuspiciousObjects(id).Do(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	analysis.SuspiciousObjects = suspiciousObjects.Items
+	progress(StageSuspiciousObjects, 0)
+
+	if a.DownloadReport {
+		path := filepath.Join(a.DownloadFolder, id+".pdf")
+		if err := a.StoreReport(ctx, id, path); err != nil {
+			return fail(err)
+		}
+		analysis.ReportPath = path
+		progress(StageReport, 0)
+	}
+	if a.DownloadInvestigationPackage {
+		path := filepath.Join(a.DownloadFolder, id+".zip")
+		if err := a.StoreInvestigationPackage(ctx, id, path); err != nil {
+			return fail(err)
+		}
+		analysis.InvestigationPackagePath = path
+		progress(StageInvestigationPackage, 0)
+	}
+	return analysis, nil
+}
+
+// Wait - poll status of already submitted object until analysis is finished
+// or Timeout passes. Failed analysis is returned along with ErrSubmission
+func (a *Analyzer) Wait(ctx context.Context, id string) (*SandboxSubmissionStatusResponse, error) {
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, a.Timeout, ErrAnalysisTimeout)
+		defer cancel()
+	}
+	status, err := a.wait(ctx, id, "", func(AnalysisStage, int) {})
+	if err != nil && ctx.Err() != nil {
+		err = context.Cause(ctx)
+	}
+	return status, err
+}
+
+// StoreReport - download PDF report to given path. Requests are repeated
+// while report is not available yet
+func (a *Analyzer) StoreReport(ctx context.Context, id string, filePath string) error {
+	return a.download(ctx, func() error {
+		return a.vone.SandboxDownloadResults(id).Store(ctx, filePath)
+	})
+}
+
+// StoreInvestigationPackage - download investigation package to given path.
+// Requests are repeated while package is not available yet
+func (a *Analyzer) StoreInvestigationPackage(ctx context.Context, id string, filePath string) error {
+	return a.download(ctx, func() error {
+		return a.vone.SandboxInvestigationPackage(id).Store(ctx, filePath)
+	})
+}
+
+// wait - poll submission status until analysis is finished
+func (a *Analyzer) wait(ctx context.Context, id string, operationLocation string, progress func(AnalysisStage, int)) (*SandboxSubmissionStatusResponse, error) {
+	operation := a.vone.SandboxOperation(id, operationLocation)
+	operation.PollInterval = a.PollInterval
+	operation.MaxPollInterval = a.MaxPollInterval
+	operation.PollMultiplier = a.PollMultiplier
+	operation.OnPoll = func(status *SandboxSubmissionStatusResponse, polls int) {
+		if status.Status == StatusRunning {
+			progress(StageRunning, polls)
+		}
+	}
+	return operation.Wait(ctx)
+}
+
+// download - call store until result is available
+func (a *Analyzer) download(ctx context.Context, store func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := store()
+		var vOneErr Error
+		var httpErr *HTTPError
+		notFound := errors.As(err, &vOneErr) && vOneErr.Code == ErrorCodeNotFound ||
+			errors.As(err, &httpErr) && httpErr.Status == http.StatusNotFound
+		if !notFound || attempt == maxDownloadAttempts {
+			return err
+		}
+		if err := a.sleep(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep - wait before next request after given (one based) attempt
+func (a *Analyzer) sleep(ctx context.Context, attempt int) error {
+	delay := backoff{
+		initial:    a.PollInterval,
+		max:        a.MaxPollInterval,
+		multiplier: a.PollMultiplier,
+	}.delay(attempt)
+	return sleep(ctx, delay, nil)
+}
@@ -0,0 +1,122 @@
+package vone_test
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+)
+
+func newTestAnalyzer(v1 *vone.VOne, folder string) *vone.Analyzer {
+	analyzer := vone.NewAnalyzer(v1)
+	analyzer.PollInterval = time.Millisecond
+	analyzer.MaxPollInterval = 10 * time.Millisecond
+	analyzer.Timeout = 5 * time.Second
+	analyzer.DownloadFolder = folder
+	analyzer.DownloadReport = true
+	analyzer.DownloadInvestigationPackage = true
+	return analyzer
+}
+
+func TestAnalyzerFile(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.AnalysisPolls = 3
+	folder := t.TempDir()
+	samplePath := filepath.Join(folder, "sample.exe")
+	content := []byte("malicious content")
+	if err := os.WriteFile(samplePath, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha1.Sum(content)
+	s.SetFileVerdict(hex.EncodeToString(sum[:]), vonetest.Verdict{
+		RiskLevel:         vone.RiskLevelHigh,
+		DetectionNames:    []string{"Trojan.Test"},
+		SuspiciousObjects: []vone.SandboxSuspiciousObject{{RiskLevel: vone.RiskLevelHigh, FileSHA1: hex.EncodeToString(sum[:])}},
+	})
+	analyzer := newTestAnalyzer(s.NewVOne(), folder)
+	var stages []vone.AnalysisStage
+	analyzer.OnProgress = func(progress vone.AnalysisProgress) {
+		stages = append(stages, progress.Stage)
+	}
+	analysis, err := analyzer.AnalyzeFile(context.Background(), samplePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !analysis.Risky() || analysis.Result.DetectionNames[0] != "Trojan.Test" {
+		t.Errorf("unexpected result: %+v", analysis.Result)
+	}
+	if len(analysis.SuspiciousObjects) != 1 {
+		t.Errorf("expected 1 suspicious object, got %d", len(analysis.SuspiciousObjects))
+	}
+	if analysis.Quota.SubmissionRemainingCount != vonetest.DefaultDailyReserve-1 {
+		t.Errorf("unexpected quota: %+v", analysis.Quota)
+	}
+	if analysis.Digest.SHA1 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected digest: %+v", analysis.Digest)
+	}
+	expected := "submitted,running,running,running,analyzed,suspiciousObjects,report,investigationPackage"
+	if actual := fmt.Sprint(stages); actual != "["+strings.ReplaceAll(expected, ",", " ")+"]" {
+		t.Errorf("expected stages %s, got %s", expected, actual)
+	}
+	report, err := os.ReadFile(analysis.ReportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(report), "%PDF") {
+		t.Errorf("unexpected report: %s", report)
+	}
+	if _, err := zip.OpenReader(analysis.InvestigationPackagePath); err != nil {
+		t.Errorf("investigation package: %v", err)
+	}
+}
+
+func TestAnalyzerURL(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	folder := t.TempDir()
+	analyzer := newTestAnalyzer(s.NewVOne(), folder)
+	analysis, err := analyzer.AnalyzeURL(context.Background(), "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analysis.Risky() || analysis.ReportPath != "" {
+		t.Errorf("unexpected analysis: %+v", analysis)
+	}
+	if entries, _ := os.ReadDir(folder); len(entries) != 0 {
+		t.Errorf("expected no downloads, got %d files", len(entries))
+	}
+}
+
+func TestAnalyzerErrors(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.SetURLVerdict("https://unsupported.example.com/", vonetest.Verdict{
+		Error: &vone.Error{Code: vone.ErrorCodeUnsupported, Message: "Unsupported"},
+	})
+	analyzer := newTestAnalyzer(s.NewVOne(), t.TempDir())
+	analysis, err := analyzer.AnalyzeURL(context.Background(), "https://unsupported.example.com/")
+	if !errors.Is(err, vone.ErrSubmission) {
+		t.Errorf("expected ErrSubmission, got %v", err)
+	}
+	if analysis.Status == nil || analysis.Status.Status != vone.StatusFailed {
+		t.Errorf("expected failed status, got %+v", analysis.Status)
+	}
+
+	s.AnalysisPolls = 1000
+	analyzer.Timeout = 50 * time.Millisecond
+	_, err = analyzer.AnalyzeURL(context.Background(), "https://slow.example.com/")
+	if !errors.Is(err, vone.ErrAnalysisTimeout) {
+		t.Errorf("expected ErrAnalysisTimeout, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Sandbox API capabilities
+
+	archive_analyzer.go - analyze archive files one by one and aggregate verdicts
+*/
+
+package vone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// ErrNoAnalysisResult - FileAnalyzer returned neither error nor analysis result
+var ErrNoAnalysisResult = errors.New("no analysis result")
+
+// FileAnalyzer - analyze single file. Implemented by Analyzer and CachingAnalyzer
+type FileAnalyzer interface {
+	AnalyzeFile(ctx context.Context, filePath string) (*Analysis, error)
+}
+
+var (
+	_ FileAnalyzer = &Analyzer{}
+	_ FileAnalyzer = &CachingAnalyzer{}
+)
+
+// MemberAnalysis - analysis of one file of archive
+type MemberAnalysis struct {
+	ArchiveMember
+	Analysis *Analysis // Analysis of file. Can be incomplete if Err is not nil
+	Err      error     // Error of file analysis
+}
+
+// ArchiveAnalysis - aggregated verdict for archive
+type ArchiveAnalysis struct {
+	Archive        string           // Archive path
+	Members        []MemberAnalysis // Analysis of each file in the order of archive
+	RiskLevel      RiskLevel        // Highest risk level of analyzed files
+	DetectionNames []string         // Detection names of all files
+	ThreatTypes    []string         // Threat types of all files
+}
+
+// Risky - return whether any of archive files has any risk level
+func (a *ArchiveAnalysis) Risky() bool {
+	return a.RiskLevel != RiskLevelNoRisk
+}
+
+// ArchiveAnalyzer - expand zip or tar.gz archive locally and analyze each of
+// its files separately. Usage:
+//
+//	analyzer := vone.NewArchiveAnalyzer(vone.NewAnalyzer(v1))
+//	analyzer.Password = "infected"
+//	analysis, err := analyzer.AnalyzeArchive(ctx, "samples.zip")
+//	if analysis.Risky() {
+//	...
+type ArchiveAnalyzer struct {
+	// Analyzer - analyzer for extracted files
+	Analyzer FileAnalyzer
+	// Password - password of encrypted zip archives
+	Password string
+	// Limits - zip bomb protection
+	Limits ArchiveLimits
+	// Concurrency - maximum amount of files analyzed simultaneously
+	Concurrency int
+	// TempFolder - folder to extract files to. Empty string means default temporary folder
+	TempFolder string
+}
+
+// NewArchiveAnalyzer - create archive analyzer with DefaultArchiveLimits
+// analyzing up to 4 files simultaneously
+func NewArchiveAnalyzer(analyzer FileAnalyzer) *ArchiveAnalyzer {
+	return &ArchiveAnalyzer{
+		Analyzer:    analyzer,
+		Limits:      DefaultArchiveLimits(),
+		Concurrency: 4,
+	}
+}
+
+// AnalyzeArchive - extract files of archive and analyze each of them. If any
+// of archive limits is exceeded, nothing is submitted and error wrapping
+// ErrArchiveLimit is returned. If analysis of some files failed, their errors
+// are returned along with aggregated verdict of other files. If ctx is done,
+// files not yet started get ctx error
+func (a *ArchiveAnalyzer) AnalyzeArchive(ctx context.Context, archivePath string) (*ArchiveAnalysis, error) {
+	folder, err := os.MkdirTemp(a.TempFolder, "vone-archive-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(folder)
+	members, err := ExpandArchive(archivePath, folder, a.Password, a.Limits)
+	if err != nil {
+		return nil, err
+	}
+	analysis := &ArchiveAnalysis{
+		Archive: archivePath,
+		Members: make([]MemberAnalysis, len(members)),
+	}
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, max(a.Concurrency, 1))
+	for i, member := range members {
+		acquired := false
+		if ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case semaphore <- struct{}{}:
+				acquired = true
+			}
+		}
+		if !acquired {
+			analysis.Members[i] = MemberAnalysis{
+				ArchiveMember: member,
+				Err:           fmt.Errorf("%s: %s: %w", archivePath, member.Name, ctx.Err()),
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			memberAnalysis, err := a.Analyzer.AnalyzeFile(ctx, member.Path)
+			if err == nil && (memberAnalysis == nil || memberAnalysis.Result == nil) {
+				// FileAnalyzer can be implemented outside of this package
+				err = ErrNoAnalysisResult
+			}
+			if err != nil {
+				err = fmt.Errorf("%s: %s: %w", archivePath, member.Name, err)
+			}
+			analysis.Members[i] = MemberAnalysis{
+				ArchiveMember: member,
+				Analysis:      memberAnalysis,
+				Err:           err,
+			}
+		}()
+	}
+	wg.Wait()
+	return analysis, analysis.aggregate()
+}
+
+// aggregate - collect verdicts of analyzed files and return errors of failed ones
+func (a *ArchiveAnalysis) aggregate() error {
+	a.RiskLevel = RiskLevelNoRisk
+	var errs []error
+	for _, member := range a.Members {
+		if member.Err != nil {
+			errs = append(errs, member.Err)
+			continue
+		}
+		result := member.Analysis.Result
+		// lower value means higher risk
+		a.RiskLevel = min(a.RiskLevel, result.RiskLevel)
+		for _, name := range result.DetectionNames {
+			if !slices.Contains(a.DetectionNames, name) {
+				a.DetectionNames = append(a.DetectionNames, name)
+			}
+		}
+		for _, threatType := range result.ThreatTypes {
+			if !slices.Contains(a.ThreatTypes, threatType) {
+				a.ThreatTypes = append(a.ThreatTypes, threatType)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
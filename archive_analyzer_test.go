@@ -0,0 +1,168 @@
+package vone_test
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+)
+
+// writeTestZip - create zip archive with given files
+func writeTestZip(t *testing.T, archivePath string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fileAnalyzerFunc - FileAnalyzer implemented by function
+type fileAnalyzerFunc func(ctx context.Context, filePath string) (*vone.Analysis, error)
+
+func (f fileAnalyzerFunc) AnalyzeFile(ctx context.Context, filePath string) (*vone.Analysis, error) {
+	return f(ctx, filePath)
+}
+
+func TestArchiveAnalyzer(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	folder := t.TempDir()
+	files := map[string]string{
+		"clean.txt":   "clean content",
+		"dir/mal.exe": "malicious content",
+		"dir/pup.exe": "unwanted content",
+	}
+	archivePath := filepath.Join(folder, "samples.zip")
+	writeTestZip(t, archivePath, files)
+	sha1Of := func(content string) string {
+		sum := sha1.Sum([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+	s.SetFileVerdict(sha1Of("malicious content"), vonetest.Verdict{
+		RiskLevel:      vone.RiskLevelHigh,
+		DetectionNames: []string{"Trojan.Test"},
+		ThreatTypes:    []string{"Trojan"},
+	})
+	s.SetFileVerdict(sha1Of("unwanted content"), vonetest.Verdict{
+		RiskLevel:      vone.RiskLevelLow,
+		DetectionNames: []string{"PUA.Test"},
+		ThreatTypes:    []string{"Trojan"},
+	})
+	analyzer := vone.NewArchiveAnalyzer(newTestAnalyzer(s.NewVOne(), folder))
+	analysis, err := analyzer.AnalyzeArchive(context.Background(), archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(analysis.Members) != len(files) {
+		t.Fatalf("expected %d members, got %d", len(files), len(analysis.Members))
+	}
+	for _, member := range analysis.Members {
+		if member.Digest.SHA1 != sha1Of(files[member.Name]) || member.Analysis.Result.Digest.SHA1 != member.Digest.SHA1 {
+			t.Errorf("%s: wrong digest: %+v", member.Name, member.Digest)
+		}
+	}
+	if analysis.RiskLevel != vone.RiskLevelHigh || !analysis.Risky() {
+		t.Errorf("expected high risk, got %v", analysis.RiskLevel)
+	}
+	slices.Sort(analysis.DetectionNames)
+	if fmt.Sprint(analysis.DetectionNames) != "[PUA.Test Trojan.Test]" || fmt.Sprint(analysis.ThreatTypes) != "[Trojan]" {
+		t.Errorf("unexpected detections: %v %v", analysis.DetectionNames, analysis.ThreatTypes)
+	}
+	if count := s.RequestCount("/v3.0/sandbox/files/analyze"); count != len(files) {
+		t.Errorf("expected %d submissions, got %d", len(files), count)
+	}
+
+	analyzer.Limits.MaxMembers = 2
+	if _, err := analyzer.AnalyzeArchive(context.Background(), archivePath); !errors.Is(err, vone.ErrArchiveLimit) {
+		t.Errorf("expected ErrArchiveLimit, got %v", err)
+	}
+	if count := s.RequestCount("/v3.0/sandbox/files/analyze"); count != len(files) {
+		t.Errorf("archive exceeding limits should not be submitted")
+	}
+}
+
+func TestArchiveAnalyzerNoResult(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "samples.zip")
+	writeTestZip(t, archivePath, map[string]string{
+		"nil.txt":    "nil analysis",
+		"empty.txt":  "empty analysis",
+		"result.txt": "result",
+	})
+	analyzer := vone.NewArchiveAnalyzer(fileAnalyzerFunc(func(ctx context.Context, filePath string) (*vone.Analysis, error) {
+		switch filepath.Base(filePath) {
+		case "nil.txt":
+			return nil, nil
+		case "empty.txt":
+			return &vone.Analysis{}, nil
+		}
+		return &vone.Analysis{Result: &vone.SandboxAnalysisResultsResponseItem{RiskLevel: vone.RiskLevelMedium}}, nil
+	}))
+	analysis, err := analyzer.AnalyzeArchive(context.Background(), archivePath)
+	if !errors.Is(err, vone.ErrNoAnalysisResult) {
+		t.Errorf("expected ErrNoAnalysisResult, got %v", err)
+	}
+	failed := 0
+	for _, member := range analysis.Members {
+		if errors.Is(member.Err, vone.ErrNoAnalysisResult) {
+			failed++
+		}
+	}
+	if failed != 2 {
+		t.Errorf("expected 2 members without result, got %d", failed)
+	}
+	if analysis.RiskLevel != vone.RiskLevelMedium {
+		t.Errorf("expected medium risk, got %v", analysis.RiskLevel)
+	}
+}
+
+func TestArchiveAnalyzerCanceled(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "samples.zip")
+	files := make(map[string]string)
+	for i := range 10 {
+		files[fmt.Sprintf("file%d.txt", i)] = fmt.Sprint(i)
+	}
+	writeTestZip(t, archivePath, files)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls atomic.Int32
+	analyzer := vone.NewArchiveAnalyzer(fileAnalyzerFunc(func(ctx context.Context, filePath string) (*vone.Analysis, error) {
+		calls.Add(1)
+		cancel()
+		return nil, ctx.Err()
+	}))
+	analyzer.Concurrency = 1
+	analysis, err := analyzer.AnalyzeArchive(ctx, archivePath)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected analysis to stop after cancel, got %d calls", n)
+	}
+	for _, member := range analysis.Members {
+		if !errors.Is(member.Err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", member.Name, member.Err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Sandbox API capabilities
+
+	caching_analyzer.go - skip submission of files with cached verdict
+*/
+
+package vone
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Forever - CacheTTL value for verdicts that never expire
+const Forever time.Duration = -1
+
+// CacheTTL - how long cached verdict of each risk level is used instead of
+// submitting file again. Zero or missing duration means verdict of this
+// risk level is always re-checked
+type CacheTTL map[RiskLevel]time.Duration
+
+// DefaultCacheTTL - keep risky verdicts forever and re-check files without
+// risk after 7 days
+func DefaultCacheTTL() CacheTTL {
+	return CacheTTL{
+		RiskLevelHigh:   Forever,
+		RiskLevelMedium: Forever,
+		RiskLevelLow:    Forever,
+		RiskLevelNoRisk: 7 * 24 * time.Hour,
+	}
+}
+
+// Valid - check whether verdict of given risk level cached at updated time can be used
+func (t CacheTTL) Valid(riskLevel RiskLevel, updated time.Time, now time.Time) bool {
+	ttl := t[riskLevel]
+	if ttl == Forever {
+		return true
+	}
+	return ttl > 0 && now.Sub(updated) < ttl
+}
+
+// CachingAnalyzer - Analyzer front-end that hashes file locally and returns
+// cached verdict if it is not expired. Otherwise file is analyzed and its
+// verdict is added to cache. Usage:
+//
+//	analyzer := vone.NewCachingAnalyzer(vone.NewAnalyzer(v1), cache)
+//	analysis, err := analyzer.AnalyzeFile(ctx, "sample.exe")
+//	if analysis.FromCache {
+//	...
+type CachingAnalyzer struct {
+	Analyzer *Analyzer
+	Cache    *Cache
+	TTL      CacheTTL
+	// KeepArtifacts - keep content of PDF report and investigation package in
+	// cache, not only their paths and hashes, so they are restored even if
+	// downloaded files are removed
+	KeepArtifacts bool
+
+	now func() time.Time
+}
+
+// NewCachingAnalyzer - create caching analyzer with DefaultCacheTTL
+func NewCachingAnalyzer(analyzer *Analyzer, cache *Cache) *CachingAnalyzer {
+	return &CachingAnalyzer{
+		Analyzer: analyzer,
+		Cache:    cache,
+		TTL:      DefaultCacheTTL(),
+		now:      time.Now,
+	}
+}
+
+// AnalyzeFile - return cached verdict for file or submit it to sandbox
+func (a *CachingAnalyzer) AnalyzeFile(ctx context.Context, filePath string) (*Analysis, error) {
+	sha1, err := fileSHA1(filePath)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := a.Cache.QueryEntry(ctx, sha1)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil && a.TTL.Valid(entry.Result.RiskLevel, entry.Updated, a.now()) {
+		return a.cachedAnalysis(filePath, entry)
+	}
+	analysis, err := a.Analyzer.AnalyzeFile(ctx, filePath)
+	if err != nil {
+		return analysis, err
+	}
+	entry, err = a.cacheEntry(analysis)
+	if err != nil {
+		return analysis, fmt.Errorf("%s: %w", filePath, err)
+	}
+	if err := a.Cache.AddEntry(ctx, entry); err != nil {
+		return analysis, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return analysis, nil
+}
+
+// cacheEntry - make cache entry of analysis hashing its downloaded artifacts
+func (a *CachingAnalyzer) cacheEntry(analysis *Analysis) (entry *CacheEntry, err error) {
+	entry = &CacheEntry{
+		Result:            analysis.Result,
+		SuspiciousObjects: analysis.SuspiciousObjects,
+	}
+	if analysis.ReportPath != "" {
+		if entry.Report, err = NewCacheArtifact(analysis.ReportPath, a.KeepArtifacts); err != nil {
+			return nil, err
+		}
+	}
+	if analysis.InvestigationPackagePath != "" {
+		if entry.InvestigationPackage, err = NewCacheArtifact(analysis.InvestigationPackagePath, a.KeepArtifacts); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+// cachedAnalysis - make analysis of cache entry. Artifacts which files are
+// changed and content is not kept in cache are skipped
+func (a *CachingAnalyzer) cachedAnalysis(filePath string, entry *CacheEntry) (*Analysis, error) {
+	analysis := &Analysis{
+		Object:            filePath,
+		ID:                entry.Result.ID,
+		Result:            entry.Result,
+		SuspiciousObjects: entry.SuspiciousObjects,
+		FromCache:         true,
+	}
+	restore := func(artifact *CacheArtifact, ext string) (string, error) {
+		if artifact == nil {
+			return "", nil
+		}
+		path, err := artifact.Restore(filepath.Join(a.Analyzer.DownloadFolder, entry.Result.ID+ext))
+		if errors.Is(err, ErrNotFound) {
+			return "", nil
+		}
+		return path, err
+	}
+	var err error
+	if analysis.ReportPath, err = restore(entry.Report, ".pdf"); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	if analysis.InvestigationPackagePath, err = restore(entry.InvestigationPackage, ".zip"); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return analysis, nil
+}
+
+// fileSHA1 - return hex encoded SHA1 of file contents
+func fileSHA1(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha1.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("%s: %w", filePath, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,154 @@
+package vone_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+	_ "modernc.org/sqlite"
+)
+
+func TestCacheTTLValid(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	ttl := vone.DefaultCacheTTL()
+	testCases := []struct {
+		riskLevel vone.RiskLevel
+		age       time.Duration
+		expected  bool
+	}{
+		{vone.RiskLevelHigh, 1000 * 24 * time.Hour, true},
+		{vone.RiskLevelLow, time.Hour, true},
+		{vone.RiskLevelNoRisk, 6 * 24 * time.Hour, true},
+		{vone.RiskLevelNoRisk, 8 * 24 * time.Hour, false},
+	}
+	for _, tc := range testCases {
+		if actual := ttl.Valid(tc.riskLevel, now.Add(-tc.age), now); actual != tc.expected {
+			t.Errorf("%v %v: expected %v, got %v", tc.riskLevel, tc.age, tc.expected, actual)
+		}
+	}
+	if (vone.CacheTTL{}).Valid(vone.RiskLevelHigh, now, now) {
+		t.Errorf("verdict without TTL should not be valid")
+	}
+}
+
+func TestCachingAnalyzer(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	folder := t.TempDir()
+	dbPath := filepath.Join(folder, "cache.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	cache, err := vone.NewCache(db, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samplePath := filepath.Join(folder, "sample.exe")
+	if err := os.WriteFile(samplePath, []byte("sample"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	analyzer := vone.NewCachingAnalyzer(newTestAnalyzer(s.NewVOne(), folder), cache)
+	ctx := context.Background()
+	for i, expected := range []bool{false, true} {
+		analysis, err := analyzer.AnalyzeFile(ctx, samplePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if analysis.FromCache != expected {
+			t.Errorf("%d: expected FromCache %v", i, expected)
+		}
+		if analysis.Result.RiskLevel != vone.RiskLevelNoRisk {
+			t.Errorf("%d: unexpected risk level %v", i, analysis.Result.RiskLevel)
+		}
+	}
+	if actual := s.RequestCount("/v3.0/sandbox/files/analyze"); actual != 1 {
+		t.Errorf("expected 1 submission, got %d", actual)
+	}
+	analyzer.TTL = vone.CacheTTL{vone.RiskLevelNoRisk: 0}
+	analysis, err := analyzer.AnalyzeFile(ctx, samplePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analysis.FromCache {
+		t.Errorf("expected verdict to be re-checked")
+	}
+	if actual := s.RequestCount("/v3.0/sandbox/files/analyze"); actual != 2 {
+		t.Errorf("expected 2 submissions, got %d", actual)
+	}
+}
+
+func TestCachingAnalyzerArtifacts(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	folder := t.TempDir()
+	dbPath := filepath.Join(folder, "cache.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	cache, err := vone.NewCache(db, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("malicious sample")
+	sum := sha1.Sum(content)
+	s.SetFileVerdict(hex.EncodeToString(sum[:]), vonetest.Verdict{
+		RiskLevel:      vone.RiskLevelHigh,
+		DetectionNames: []string{"Trojan.Test"},
+		SuspiciousObjects: []vone.SandboxSuspiciousObject{
+			{RiskLevel: vone.RiskLevelHigh, Domain: "evil.example.com"},
+			{RiskLevel: vone.RiskLevelMedium, IP: "192.0.2.1"},
+		},
+	})
+	samplePath := filepath.Join(folder, "sample.exe")
+	if err := os.WriteFile(samplePath, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	analyzer := vone.NewCachingAnalyzer(newTestAnalyzer(s.NewVOne(), folder), cache)
+	analyzer.KeepArtifacts = true
+	ctx := context.Background()
+	analysis, err := analyzer.AnalyzeFile(ctx, samplePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := os.ReadFile(analysis.ReportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(analysis.ReportPath)
+	os.Remove(analysis.InvestigationPackagePath)
+
+	cached, err := analyzer.AnalyzeFile(ctx, samplePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cached.FromCache || cached.ID != analysis.ID {
+		t.Fatalf("unexpected cached analysis: %+v", cached)
+	}
+	if len(cached.SuspiciousObjects) != 2 || cached.SuspiciousObjects[0].Domain != "evil.example.com" {
+		t.Errorf("wrong suspicious objects: %+v", cached.SuspiciousObjects)
+	}
+	restored, err := os.ReadFile(cached.ReportPath)
+	if err != nil || !bytes.Equal(restored, report) {
+		t.Errorf("report is not restored: %v", err)
+	}
+	if _, err := os.Stat(cached.InvestigationPackagePath); err != nil {
+		t.Errorf("investigation package is not restored: %v", err)
+	}
+	for _, path := range []string{"/suspiciousObjects", "/report", "/investigationPackage"} {
+		if count := s.RequestCount("/v3.0/sandbox/analysisResults/" + analysis.ID + path); count != 1 {
+			t.Errorf("%s: expected 1 request, got %d", path, count)
+		}
+	}
+}
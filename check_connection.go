@@ -40,6 +40,10 @@ func (s *sandboxCheckConnectionRequest) url() string {
 	return "/v3.0/healthcheck/connectivity"
 }
 
+func (s *sandboxCheckConnectionRequest) operation() string {
+	return "CheckConnection"
+}
+
 func (f *sandboxCheckConnectionRequest) responseStruct() any {
 	return &f.response
 }
@@ -0,0 +1,189 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	checkpoint.go - Paginator position storage to resume iteration after restart
+*/
+
+package vone
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// ErrCheckpointMismatch - stored checkpoint was made for other query
+var ErrCheckpointMismatch = errors.New("checkpoint query does not match")
+
+// Checkpoint - position of Paginator.Range
+type Checkpoint struct {
+	Operation string            `json:"operation"` // Name of VOne method created request
+	Query     map[string]string `json:"query"`     // Request headers and parameters
+	PageLink  string            `json:"pageLink"`  // Link of current page. Empty for first page
+	Offset    int               `json:"offset"`    // Amount of items of current page already yielded
+	Yielded   int               `json:"yielded"`   // Amount of items yielded in total
+	Done      bool              `json:"done"`      // All items are yielded
+	Updated   time.Time         `json:"updated"`   // Time checkpoint was made
+}
+
+// matches - check whether checkpoint was made for given query
+func (c *Checkpoint) matches(operation string, query map[string]string) error {
+	if c.Operation != operation {
+		return fmt.Errorf("%w: operation %s, expected %s", ErrCheckpointMismatch, c.Operation, operation)
+	}
+	if !maps.Equal(c.Query, query) {
+		return fmt.Errorf("%w: %v, expected %v", ErrCheckpointMismatch, c.Query, query)
+	}
+	return nil
+}
+
+// CheckpointStore - storage of Paginator checkpoints
+type CheckpointStore interface {
+	// Save - store checkpoint under given key replacing previous one
+	Save(ctx context.Context, key string, checkpoint *Checkpoint) error
+	// Load - return checkpoint stored under given key or nil if there is none
+	Load(ctx context.Context, key string) (*Checkpoint, error)
+	// Delete - remove checkpoint. Deleting missing checkpoint is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// FileCheckpointStore - keep each checkpoint as JSON file in folder
+type FileCheckpointStore struct {
+	folder string
+}
+
+var _ CheckpointStore = &FileCheckpointStore{}
+
+// NewFileCheckpointStore - create store keeping checkpoints in folder.
+// Folder is created if needed
+func NewFileCheckpointStore(folder string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(folder, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCheckpointStore{folder: folder}, nil
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func (s *FileCheckpointStore) path(key string) string {
+	return filepath.Join(s.folder, unsafeFileNameChars.ReplaceAllString(key, "_")+".json")
+}
+
+// Save - implement CheckpointStore. File is replaced atomically
+func (s *FileCheckpointStore) Save(ctx context.Context, key string, checkpoint *Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.folder, "checkpoint-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path(key))
+}
+
+// Load - implement CheckpointStore
+func (s *FileCheckpointStore) Load(ctx context.Context, key string) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path(key), err)
+	}
+	return &checkpoint, nil
+}
+
+// Delete - implement CheckpointStore
+func (s *FileCheckpointStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// SQLCheckpointStore - keep checkpoints in checkpoints table of SQL database
+// (SQLite or PostgreSQL)
+type SQLCheckpointStore struct {
+	dbPath string
+	db     *sql.DB
+}
+
+var _ CheckpointStore = &SQLCheckpointStore{}
+
+// NewSQLCheckpointStore - create checkpoints table if needed and return store using it
+func NewSQLCheckpointStore(db *sql.DB, dbPath string) (*SQLCheckpointStore, error) {
+	stmt := `CREATE TABLE IF NOT EXISTS checkpoints (
+		name TEXT NOT NULL PRIMARY KEY,
+		data TEXT NOT NULL,
+		updated TIMESTAMP NOT NULL
+		)`
+	if _, err := db.Exec(stmt); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbPath, err)
+	}
+	return &SQLCheckpointStore{dbPath: dbPath, db: db}, nil
+}
+
+// Save - implement CheckpointStore
+func (s *SQLCheckpointStore) Save(ctx context.Context, key string, checkpoint *Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO checkpoints (name, data, updated) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET data = excluded.data, updated = excluded.updated`,
+		key, string(data), checkpoint.Updated)
+	return s.error("Save", err)
+}
+
+// Load - implement CheckpointStore
+func (s *SQLCheckpointStore) Load(ctx context.Context, key string) (*Checkpoint, error) {
+	var data string
+	row := s.db.QueryRowContext(ctx, "SELECT data FROM checkpoints WHERE name = $1", key)
+	err := row.Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, s.error("Load", err)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal([]byte(data), &checkpoint); err != nil {
+		return nil, s.error("Load", err)
+	}
+	return &checkpoint, nil
+}
+
+// Delete - implement CheckpointStore
+func (s *SQLCheckpointStore) Delete(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM checkpoints WHERE name = $1", key)
+	return s.error("Delete", err)
+}
+
+func (s *SQLCheckpointStore) error(message string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %s: %w", s.dbPath, message, err)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/spf13/viper"
+)
+
+// cacheActions - subcommands of cache command
+var cacheActions = []string{"export", "import", "stats", "cleanup"}
+
+type commandCache struct {
+	baseCommand
+	action string
+	cache  *vone.Cache
+}
+
+func newCommandCache() *commandCache {
+	c := &commandCache{}
+	c.SetupLocal(cmdCache, "Export, import, count or clean up cached sandbox verdicts: cache {export|import|stats|cleanup}")
+	c.fs.String(flagCacheDB, "", "SQLite cache database path")
+	c.fs.String(flagFileName, "", "File to export cache to or import from (default stdout/stdin)")
+	c.fs.String(flagFormat, vone.CacheFormatJSONL.String(), "Export/import format (jsonl or csv)")
+	c.fs.String(flagConflict, vone.CacheConflictNewest.String(), "What to do with imported verdicts of already cached files (newest, overwrite or skip)")
+	c.fs.Duration(flagOlderThan, 30*24*time.Hour, "Remove verdicts cached earlier than this duration ago")
+	return c
+}
+
+func (c *commandCache) Init(args []string) error {
+	if err := c.InitLocal(args); err != nil {
+		return err
+	}
+	c.action = c.fs.Arg(0)
+	if !slices.Contains(cacheActions, c.action) {
+		return fmt.Errorf("unknown cache action: \"%s\" (expected one of %v)", c.action, cacheActions)
+	}
+	dbPath := viper.GetString(flagCacheDB)
+	if dbPath == "" {
+		return errors.New("missing cache database path")
+	}
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(10000)")
+	if err != nil {
+		return err
+	}
+	c.cache, err = vone.NewCache(db, dbPath)
+	return err
+}
+
+func (c *commandCache) Execute() error {
+	defer c.cache.Close()
+	ctx := context.TODO()
+	switch c.action {
+	case "export":
+		return c.Export(ctx)
+	case "import":
+		return c.Import(ctx)
+	case "stats":
+		return c.Stats(ctx)
+	default:
+		return c.Cleanup(ctx)
+	}
+}
+
+func (c *commandCache) Export(ctx context.Context) error {
+	format, err := vone.ParseCacheFormat(viper.GetString(flagFormat))
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if fileName := viper.GetString(flagFileName); fileName != "" {
+		f, err := os.Create(fileName)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	count, err := c.cache.Export(ctx, w, format)
+	if err != nil {
+		return err
+	}
+	log.Printf("Exported verdicts: %d", count)
+	return nil
+}
+
+func (c *commandCache) Import(ctx context.Context) error {
+	format, err := vone.ParseCacheFormat(viper.GetString(flagFormat))
+	if err != nil {
+		return err
+	}
+	conflict, err := vone.ParseCacheConflict(viper.GetString(flagConflict))
+	if err != nil {
+		return err
+	}
+	var r io.Reader = os.Stdin
+	if fileName := viper.GetString(flagFileName); fileName != "" {
+		f, err := os.Open(fileName)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	stats, err := c.cache.Import(ctx, r, format, conflict)
+	log.Printf("Added: %d, replaced: %d, skipped: %d", stats.Added, stats.Replaced, stats.Skipped)
+	return err
+}
+
+func (c *commandCache) Stats(ctx context.Context) error {
+	count, err := c.cache.Count(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Cached verdicts: %d", count)
+	byRiskLevel := make(map[vone.RiskLevel]int)
+	var oldest, newest time.Time
+	err = c.cache.Iterate(ctx, func(data *vone.SandboxAnalysisResultsResponseItem, updated time.Time) error {
+		byRiskLevel[data.RiskLevel]++
+		if oldest.IsZero() || updated.Before(oldest) {
+			oldest = updated
+		}
+		if updated.After(newest) {
+			newest = updated
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, riskLevel := range []vone.RiskLevel{vone.RiskLevelHigh, vone.RiskLevelMedium, vone.RiskLevelLow, vone.RiskLevelNoRisk} {
+		log.Printf("Risk level %s: %d", riskLevel, byRiskLevel[riskLevel])
+	}
+	if count > 0 {
+		log.Printf("Cached from %v to %v", oldest, newest)
+	}
+	return nil
+}
+
+func (c *commandCache) Cleanup(ctx context.Context) error {
+	before, err := c.cache.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.cache.Cleanup(ctx, time.Now().Add(-viper.GetDuration(flagOlderThan))); err != nil {
+		return err
+	}
+	after, err := c.cache.Count(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Removed verdicts: %d, left: %d", before-after, after)
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/viper"
+)
+
+// openAlertsFilter - workbench alerts that are not closed
+const openAlertsFilter = "status eq 'Open' or status eq 'InProgress'"
+
+// Minimal risk scores of Vision One risk levels as shown in Attack Surface Risk
+// Management console: low 0-39, medium 40-69, high 70-100
+const (
+	highRiskScore   = 70
+	mediumRiskScore = 40
+)
+
+// riskBucket - Vision One risk level of device risk score
+func riskBucket(riskScore int) string {
+	switch {
+	case riskScore >= highRiskScore:
+		return "high"
+	case riskScore >= mediumRiskScore:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+type commandExporter struct {
+	baseCommand
+	registry *prometheus.Registry
+
+	reserve     prometheus.Gauge
+	remaining   prometheus.Gauge
+	exemption   prometheus.Gauge
+	devices     *prometheus.GaugeVec
+	alerts      *prometheus.GaugeVec
+	endpoints   *prometheus.GaugeVec
+	errorsCount *prometheus.CounterVec
+	lastSuccess *prometheus.GaugeVec
+}
+
+func newCommandExporter() *commandExporter {
+	c := &commandExporter{}
+	c.Setup(cmdExporter, "Run Prometheus exporter of quota, high risk devices, workbench alerts and endpoints")
+	c.fs.String(flagListen, ":9090", "Address to serve /metrics on")
+	c.fs.Duration(flagInterval, 5*time.Minute, "Interval between Vision One polls")
+
+	c.registry = prometheus.NewRegistry()
+	c.reserve = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "vone_sandbox_submission_reserve_count",
+		Help: "Daily sandbox submission quota",
+	})
+	c.remaining = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "vone_sandbox_submission_remaining_count",
+		Help: "Remaining daily sandbox submissions",
+	})
+	c.exemption = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "vone_sandbox_submission_exemption_count",
+		Help: "Sandbox submissions not counted in quota",
+	})
+	c.devices = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "vone_high_risk_devices",
+		Help: "High risk devices by risk level (low 0-39, medium 40-69, high 70-100)",
+	}, []string{"risk"})
+	c.alerts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "vone_workbench_open_alerts",
+		Help: "Not closed workbench alerts by severity and status",
+	}, []string{"severity", "status"})
+	c.endpoints = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "vone_endpoints",
+		Help: "Endpoints by EPP agent status and component update status",
+	}, []string{"status", "component_update_status"})
+	c.errorsCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "vone_exporter_errors_total",
+		Help: "Failed Vision One polls by source",
+	}, []string{"source"})
+	c.lastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "vone_exporter_last_success_timestamp_seconds",
+		Help: "Time of last successful Vision One poll by source",
+	}, []string{"source"})
+	c.registry.MustRegister(c.reserve, c.remaining, c.exemption, c.devices,
+		c.alerts, c.endpoints, c.errorsCount, c.lastSuccess)
+	return c
+}
+
+func (c *commandExporter) Execute() error {
+	interval := viper.GetDuration(flagInterval)
+	if interval <= 0 {
+		return errors.New("interval should be positive")
+	}
+	go func() {
+		for {
+			c.poll(context.TODO())
+			time.Sleep(interval)
+		}
+	}()
+	http.Handle("/metrics", promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{}))
+	listen := viper.GetString(flagListen)
+	log.Printf("Serving metrics on %s/metrics", listen)
+	return http.ListenAndServe(listen, nil)
+}
+
+// poll - update all metrics
+func (c *commandExporter) poll(ctx context.Context) {
+	sources := []struct {
+		name string
+		poll func(context.Context) error
+	}{
+		{"quota", c.pollQuota},
+		{"high_risk_devices", c.pollHighRiskDevices},
+		{"workbench_alerts", c.pollAlerts},
+		{"endpoints", c.pollEndpoints},
+	}
+	for _, source := range sources {
+		if err := source.poll(ctx); err != nil {
+			log.Printf("%s: %v", source.name, err)
+			c.errorsCount.WithLabelValues(source.name).Inc()
+			continue
+		}
+		c.lastSuccess.WithLabelValues(source.name).SetToCurrentTime()
+	}
+}
+
+func (c *commandExporter) pollQuota(ctx context.Context) error {
+	quota, err := c.visionOne.SandboxDailyReserve().Do(ctx)
+	if err != nil {
+		return err
+	}
+	c.reserve.Set(float64(quota.SubmissionReserveCount))
+	c.remaining.Set(float64(quota.SubmissionRemainingCount))
+	c.exemption.Set(float64(quota.SubmissionExemptionCount))
+	return nil
+}
+
+func (c *commandExporter) pollHighRiskDevices(ctx context.Context) error {
+	counts := map[string]int{"low": 0, "medium": 0, "high": 0}
+	for device, err := range c.visionOne.HighRiskDevices().Range(ctx) {
+		if err != nil {
+			return err
+		}
+		counts[riskBucket(device.RiskScore)]++
+	}
+	c.devices.Reset()
+	for risk, count := range counts {
+		c.devices.WithLabelValues(risk).Set(float64(count))
+	}
+	return nil
+}
+
+func (c *commandExporter) pollAlerts(ctx context.Context) error {
+	counts := make(map[[2]string]int)
+	for alert, err := range c.visionOne.WorkbenchListAlerts().Filter(openAlertsFilter).Paginator().Range(ctx) {
+		if err != nil {
+			return err
+		}
+		counts[[2]string{alert.Severity, alert.Status}]++
+	}
+	c.alerts.Reset()
+	for labels, count := range counts {
+		c.alerts.WithLabelValues(labels[0], labels[1]).Set(float64(count))
+	}
+	return nil
+}
+
+func (c *commandExporter) pollEndpoints(ctx context.Context) error {
+	counts := make(map[[2]string]int)
+	for endpoint, err := range c.visionOne.EndPointList().Paginator().Range(ctx) {
+		if err != nil {
+			return err
+		}
+		counts[[2]string{endpoint.EppAgent.Status, endpoint.EppAgent.ComponentUpdateStatus}]++
+	}
+	c.endpoints.Reset()
+	for labels, count := range counts {
+		c.endpoints.WithLabelValues(labels[0], labels[1]).Set(float64(count))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestRiskBucket(t *testing.T) {
+	testCases := []struct {
+		riskScore int
+		expected  string
+	}{
+		{0, "low"},
+		{mediumRiskScore - 1, "low"},
+		{mediumRiskScore, "medium"},
+		{highRiskScore - 1, "medium"},
+		{highRiskScore, "high"},
+		{100, "high"},
+	}
+	for _, tc := range testCases {
+		if actual := riskBucket(tc.riskScore); actual != tc.expected {
+			t.Errorf("%d: expected %s, got %s", tc.riskScore, tc.expected, actual)
+		}
+	}
+}
+
+func TestExporterPoll(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.SetDailyReserve(10000)
+	s.AddHighRiskDevices(
+		vone.HighRiskDevicesItem{ID: "1", RiskScore: 95},
+		vone.HighRiskDevicesItem{ID: "2", RiskScore: 70},
+		vone.HighRiskDevicesItem{ID: "3", RiskScore: 45},
+	)
+	s.AddAlerts(
+		vone.WorkbenchAlert{ID: "WB-1", Severity: "high", Status: "Open"},
+		vone.WorkbenchAlert{ID: "WB-2", Severity: "high", Status: "Open"},
+		vone.WorkbenchAlert{ID: "WB-3", Severity: "low", Status: "InProgress"},
+		vone.WorkbenchAlert{ID: "WB-4", Severity: "critical", Status: "Closed"},
+	)
+	var endpoint vone.EndpointListItem
+	endpoint.EppAgent.Status = "on"
+	endpoint.EppAgent.ComponentUpdateStatus = "onSchedule"
+	s.AddEndpoints(endpoint, endpoint)
+
+	c := newCommandExporter()
+	c.visionOne = s.NewVOne()
+	c.poll(context.Background())
+
+	w := httptest.NewRecorder()
+	promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{}).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	metrics := w.Body.String()
+	for _, expected := range []string{
+		"vone_sandbox_submission_reserve_count 10000",
+		"vone_sandbox_submission_remaining_count 10000",
+		`vone_high_risk_devices{risk="high"} 2`,
+		`vone_high_risk_devices{risk="medium"} 1`,
+		`vone_high_risk_devices{risk="low"} 0`,
+		`vone_workbench_open_alerts{severity="high",status="Open"} 2`,
+		`vone_workbench_open_alerts{severity="low",status="InProgress"} 1`,
+		`vone_endpoints{component_update_status="onSchedule",status="on"} 2`,
+		`vone_exporter_last_success_timestamp_seconds{source="endpoints"}`,
+	} {
+		if !strings.Contains(metrics, expected) {
+			t.Errorf("missing %s", expected)
+		}
+	}
+	for _, unexpected := range []string{"Closed", "vone_exporter_errors_total"} {
+		if strings.Contains(metrics, unexpected) {
+			t.Errorf("unexpected %s", unexpected)
+		}
+	}
+	if t.Failed() {
+		t.Log(metrics)
+	}
+}
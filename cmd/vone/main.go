@@ -10,6 +10,7 @@
 package main
 
 import (
+	"database/sql"
 	"fmt"
 	"io"
 	"log"
@@ -22,6 +23,7 @@ import (
 	"github.com/mpkondrashin/vone"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	_ "modernc.org/sqlite"
 )
 
 const (
@@ -41,33 +43,49 @@ const (
 	cmdPing             = "ping"
 	cmdAddEception      = "it_exception"
 	cmdGetOATEvents     = "oat"
+	cmdExporter         = "exporter"
+	cmdCache            = "cache"
 )
 
 const (
-	flagAddress       = "address"
-	flagToken         = "token"
-	flagLog           = "log"
-	flagFileName      = "filename"
-	flagMask          = "mask"
-	flagURL           = "url"
-	flagURLsFile      = "urlfile"
-	flagTimeout       = "timeout"
-	flagID            = "id"
-	flagQuery         = "query"
-	flagFilter        = "filter"
-	flagOrderBy       = "order_by"
-	flagTop           = "top"
-	flagProxy         = "proxy"
-	flagProxyUser     = "proxy_user"
-	flagProxyPassword = "proxy_password"
-	flagProxyDomain   = "proxy_domain"
-	flagSOType        = "so_type"
-	flagSO            = "so"
-	flagDescription   = "description"
-	flagDetectedStart = "detected_start"
-	flagDetectedEnd   = "detected_end"
-	flagIngestedStart = "ingested_start"
-	flagIngestedEnd   = "ingested_end"
+	flagAddress          = "address"
+	flagToken            = "token"
+	flagLog              = "log"
+	flagFileName         = "filename"
+	flagMask             = "mask"
+	flagURL              = "url"
+	flagURLsFile         = "urlfile"
+	flagTimeout          = "timeout"
+	flagID               = "id"
+	flagQuery            = "query"
+	flagFilter           = "filter"
+	flagOrderBy          = "order_by"
+	flagTop              = "top"
+	flagProxy            = "proxy"
+	flagProxyUser        = "proxy_user"
+	flagProxyPassword    = "proxy_password"
+	flagProxyDomain      = "proxy_domain"
+	flagSOType           = "so_type"
+	flagSO               = "so"
+	flagDescription      = "description"
+	flagDetectedStart    = "detected_start"
+	flagDetectedEnd      = "detected_end"
+	flagIngestedStart    = "ingested_start"
+	flagIngestedEnd      = "ingested_end"
+	flagRetries          = "retries"
+	flagRateLimitDB      = "rate_limit_db"
+	flagHeaderRateLimit  = "header_rate_limit"
+	flagListen           = "listen"
+	flagInterval         = "interval"
+	flagConcurrency      = "concurrency"
+	flagArchivePassword  = "archive_password"
+	flagDocumentPassword = "document_password"
+	flagExpand           = "expand"
+	flagSTIX             = "stix"
+	flagCacheDB          = "cache_db"
+	flagFormat           = "format"
+	flagConflict         = "conflict"
+	flagOlderThan        = "older_than"
 )
 
 type command interface {
@@ -85,23 +103,30 @@ type baseCommand struct {
 }
 
 func (c *baseCommand) Setup(name, description string) {
-	c.name = name
-	c.description = description
-	c.fs = pflag.NewFlagSet(name, pflag.ExitOnError)
+	c.SetupLocal(name, description)
 	c.fs.String(flagAddress, "", "Vision One entry point URL")
 	c.fs.String(flagToken, "", "Vision One API Token")
-	c.fs.String(flagLog, "", "Log file path")
 
 	c.fs.String(flagProxy, "", "Proxy URL (scheme://address:port)")
 	c.fs.String(flagProxyUser, "", "Proxy username")
 	c.fs.String(flagProxyPassword, "", "Proxy password")
 	c.fs.String(flagProxyDomain, "", "Proxy domain (for NTLM auth)")
+	c.fs.Int(flagRetries, 0, "Number of retries for requests failed with transient errors (0 - no retries)")
+	c.fs.Bool(flagHeaderRateLimit, false, "Delay requests according to RateLimit-* response headers instead of backing off after rate limit errors")
+	c.fs.String(flagRateLimitDB, "", "SQLite database path to share rate limit with other processes using the same token")
 
+}
+
+// SetupLocal - setup command that does not use Vision One API
+func (c *baseCommand) SetupLocal(name, description string) {
+	c.name = name
+	c.description = description
+	c.fs = pflag.NewFlagSet(name, pflag.ExitOnError)
+	c.fs.String(flagLog, "", "Log file path")
 	c.fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "%s\nAvailable options:\n", c.description)
 		c.fs.PrintDefaults()
 	}
-
 }
 
 func (c *baseCommand) Name() string {
@@ -116,7 +141,8 @@ func (c *baseCommand) String() string {
 	return c.name
 }
 
-func (c *baseCommand) Init(args []string) error {
+// InitLocal - parse options and open log file without creating Vision One client
+func (c *baseCommand) InitLocal(args []string) error {
 	err := c.fs.Parse(os.Args[2:])
 	if err != nil {
 		return err
@@ -146,12 +172,51 @@ func (c *baseCommand) Init(args []string) error {
 		}
 		//LogIt(Debug, "ReadInConfig: %v", notFoundErr)
 	}
+	//	c.ctx = context.Background()
+	//	if viper.GetBool(flagDryRun) {
+	//		c.ctx = ddan.DryRunContext(context.Background(), func(line string) {
+	//			fmt.Println(line)
+	//		})
+	//	}
+	logFilePath := viper.GetString(flagLog)
+	if logFilePath != "" {
+		logFile, err := os.Create(logFilePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.SetOutput(io.MultiWriter(os.Stdout, logFile))
+	}
+	return nil
+}
+
+func (c *baseCommand) Init(args []string) error {
+	if err := c.InitLocal(args); err != nil {
+		return err
+	}
 	c.visionOne = vone.NewVOne(
 		viper.GetString(flagAddress),
 		viper.GetString(flagToken),
 	)
-	rateLimiter := vone.NewAdaptiveRateLimiter(vone.VOneRateLimitSurpassedError, nil)
-	c.visionOne.SetRateLimiter(rateLimiter)
+	if dbPath := viper.GetString(flagRateLimitDB); dbPath != "" {
+		db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(10000)")
+		if err != nil {
+			log.Fatal(err)
+		}
+		rateLimiter, err := vone.NewSharedRateLimiter(db, dbPath, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		c.visionOne.SetRateLimiter(rateLimiter)
+	} else if viper.GetBool(flagHeaderRateLimit) {
+		c.visionOne.SetRateLimiter(vone.NewHeaderRateLimiter(nil))
+	} else {
+		c.visionOne.SetRateLimiter(vone.NewAdaptiveRateLimiter(vone.VOneRateLimitSurpassedError, nil))
+	}
+	if retries := viper.GetInt(flagRetries); retries > 0 {
+		retryPolicy := vone.DefaultRetryPolicy()
+		retryPolicy.MaxAttempts = retries + 1
+		c.visionOne.SetRetryPolicy(retryPolicy)
+	}
 
 	if viper.GetString(flagProxy) != "" {
 		u, err := url.Parse(viper.GetString(flagProxy))
@@ -181,20 +246,6 @@ func (c *baseCommand) Init(args []string) error {
 		}
 		c.visionOne.AddTransportModifier(proxy.GetModifier())
 	}
-	//	c.ctx = context.Background()
-	//	if viper.GetBool(flagDryRun) {
-	//		c.ctx = ddan.DryRunContext(context.Background(), func(line string) {
-	//			fmt.Println(line)
-	//		})
-	//	}
-	logFilePath := viper.GetString(flagLog)
-	if logFilePath != "" {
-		logFile, err := os.Create(logFilePath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.SetOutput(io.MultiWriter(os.Stdout, logFile))
-	}
 	return nil
 }
 
@@ -208,6 +259,8 @@ var commands = []command{
 	newCommandHighRiskDevices(),
 	newCommandAddIT(),
 	newCommandGetOATEvents(),
+	newCommandExporter(),
+	newCommandCache(),
 }
 
 func usage() {
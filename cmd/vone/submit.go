@@ -3,8 +3,6 @@ package main
 import (
 	"bytes"
 	"context"
-	"errors"
-	"fmt"
 	"io"
 	"log"
 	"os"
@@ -80,15 +78,36 @@ func (c *commandSubmit) ProcessURLsFile(wg *sync.WaitGroup) {
 			return
 		}
 	}
-	urls := strings.Split(string(data), "\n")
-	log.Printf("Loaded %d lines", len(urls))
-	for _, url := range urls {
+	lines := strings.Split(string(data), "\n")
+	log.Printf("Loaded %d lines", len(lines))
+	var urls []string
+	for _, url := range lines {
 		url = strings.TrimSpace(url)
 		if url == "" {
 			continue
 		}
+		urls = append(urls, url)
+	}
+	submitter := vone.NewURLBatchSubmitter(c.visionOne)
+	submitter.Concurrency = viper.GetInt(flagConcurrency)
+	analyzing := make(chan struct{}, max(submitter.Concurrency, 1))
+	for _, submission := range submitter.Submit(context.TODO(), urls) {
+		if submission.Err != nil {
+			log.Printf("%s: %v", submission.URL, submission.Err)
+			continue
+		}
+		log.Printf("%s URL accepted: %s", submission.ID, submission.Normalized)
 		wg.Add(1)
-		go c.SubmitURLGoRoutine(url, wg)
+		analyzing <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-analyzing }()
+			analysis, err := c.Analyzer().AnalyzeOperation(context.TODO(), submission.Normalized, submission.ID, submission.OperationLocation)
+			c.Output(analysis)
+			if err != nil {
+				log.Println(err)
+			}
+		}()
 	}
 }
 
@@ -124,143 +143,141 @@ func GetReader(filePath string) (io.Reader, error) {
 
 }*/
 
+func (c *commandSubmit) Analyzer() *vone.Analyzer {
+	analyzer := vone.NewAnalyzer(c.visionOne)
+	analyzer.Timeout = viper.GetDuration(flagTimeout)
+	analyzer.DownloadReport = true
+	analyzer.DownloadInvestigationPackage = true
+	analyzer.ArchivePassword = viper.GetString(flagArchivePassword)
+	analyzer.DocumentPassword = viper.GetString(flagDocumentPassword)
+	analyzer.OnProgress = func(progress vone.AnalysisProgress) {
+		switch progress.Stage {
+		case vone.StageSubmitted:
+			log.Printf("%s: Accepted %s", progress.ID, progress.Object)
+		case vone.StageRunning:
+			log.Printf("%s Status: %v", progress.ID, vone.StatusRunning)
+		case vone.StageAnalyzed:
+			log.Printf("%s Status: %v", progress.ID, vone.StatusSucceeded)
+		}
+	}
+	return analyzer
+}
+
 func (c *commandSubmit) SubmitFile(filePath string) error {
+	if viper.GetBool(flagExpand) {
+		return c.SubmitArchive(filePath)
+	}
 	log.Printf("Uploading %s", filePath)
-	f := c.visionOne.SandboxSubmitFile()
-	err := f.SetFilePath(context.Background(), filePath)
-	if err != nil {
+	analysis, err := c.Analyzer().AnalyzeFile(context.TODO(), filePath)
+	c.Output(analysis)
+	return err
+}
+
+func (c *commandSubmit) SubmitArchive(filePath string) error {
+	log.Printf("Expanding %s", filePath)
+	analyzer := vone.NewArchiveAnalyzer(c.Analyzer())
+	analyzer.Password = viper.GetString(flagArchivePassword)
+	analyzer.Concurrency = viper.GetInt(flagConcurrency)
+	analysis, err := analyzer.AnalyzeArchive(context.TODO(), filePath)
+	if analysis == nil {
 		return err
 	}
-	response, headers, err := f.Do(context.Background())
-	if err != nil {
-		return err
+	for _, member := range analysis.Members {
+		log.Printf("%s: %s SHA1: %s", filePath, member.Name, member.Digest.SHA1)
+		if member.Analysis != nil {
+			c.Output(member.Analysis)
+		}
+	}
+	log.Printf("%s RiskLevel: %s", filePath, analysis.RiskLevel)
+	if len(analysis.DetectionNames) > 0 {
+		log.Printf("%s DetectionNames: %s", filePath, strings.Join(analysis.DetectionNames, ", "))
 	}
-	id := response.ID
-	log.Printf("%s: Accepted %s accepted", id, filePath)
-	c.LogQuota(id, headers)
-	return c.ProcessObject(id)
+	return err
 }
 
 func (c *commandSubmit) SubmitURL(url string) error {
 	log.Printf("Uploading URL %s", url)
-	start := time.Now()
-	submit := c.visionOne.SandboxSubmitURLs().AddURL(url)
-	response, headers, err := submit.Do(context.Background())
-	if err != nil {
-		return fmt.Errorf("%s: %w", url, err)
-	}
-	if len(response) != 1 {
-		return fmt.Errorf("wrong response length: %d", len(response))
-	}
-	resp := response[0]
-	if vone.GetHTTPCodeRange(resp.Status) != vone.HTTPCodeSuccessRange {
-		return fmt.Errorf("%s: %s: %s", url, resp.Body.Error.Code, resp.Body.Error.Code)
+	analysis, err := c.Analyzer().AnalyzeURL(context.TODO(), url)
+	c.Output(analysis)
+	if err == nil {
+		log.Printf("%s Analysis time: %v", analysis.ID, analysis.Duration.Round(1*time.Second))
 	}
-	id := response[0].Body.ID
-	defer func() {
-		duration := time.Since(start)
-		log.Printf("%s Analysis time: %v", id, duration.Round(1*time.Second))
-	}()
-	log.Printf("%s URL accepted: %s", id, url)
-	c.LogQuota(id, headers)
-	if err := c.ProcessObject(id); err != nil {
-		return fmt.Errorf("%s %w", id, err)
-	}
-	return nil
+	return err
 }
 
-func (c *commandSubmit) LogQuota(id string, headers *vone.SandboxSubmitFileResponseHeaders) {
-	log.Printf("%s Daily quota: %d", id, headers.SubmissionReserveCount)
-	note := ""
-	if headers.SubmissionRemainingCount == 0 {
-		note = " (consider to add Credits to sandbox feature)"
+// Output - log analysis and save it as STIX bundle if requested
+func (c *commandSubmit) Output(analysis *vone.Analysis) {
+	c.LogAnalysis(analysis)
+	if err := c.SaveSTIX(analysis); err != nil {
+		log.Printf("%s STIX: %v", analysis.ID, err)
 	}
-	log.Printf("%s Quota left: %d%s", id, headers.SubmissionRemainingCount, note)
-	log.Printf("%s Today submissions: %d", id, headers.SubmissionCount)
-	log.Printf("%s Today submissions of unsupported files: %d (not accounted in quota)", id, headers.SubmissionExemptionCount)
 }
 
-func (c *commandSubmit) ProcessObject(id string) error {
-	if err := c.WaitForResult(id); err != nil {
-		return err
-	}
-	malicious, err := c.AnalysisResult(id)
-	if err != nil {
-		return err
-	}
-	if !malicious {
+func (c *commandSubmit) SaveSTIX(analysis *vone.Analysis) error {
+	folder := viper.GetString(flagSTIX)
+	bundle := analysis.STIXBundle()
+	if folder == "" || bundle == nil {
 		return nil
 	}
-	if err := c.SuspiciousObjects(id); err != nil {
+	data, err := bundle.JSON()
+	if err != nil {
 		return err
 	}
-	if err := c.PDFReport(id); err != nil {
+	if err := os.MkdirAll(folder, 0755); err != nil {
 		return err
 	}
-	if err := c.InvestigationPackage(id); err != nil {
+	path := filepath.Join(folder, analysis.ID+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
 		return err
 	}
+	log.Printf("%s STIX bundle saved: %s", analysis.ID, path)
 	return nil
 }
 
-var (
-	ErrTimeout             = errors.New("timeout")
-	ErrUnsupportedFileType = errors.New("unsupported file type")
-)
-
-func (c *commandSubmit) WaitForResult(id string) error {
-	timeout := viper.GetDuration(flagTimeout)
-	endTime := time.Now().Add(timeout)
-	for {
-		//log.Printf("SandboxSubmissionStatus(%s)", id)
-		status, err := c.visionOne.SandboxSubmissionStatus(id).Do(context.TODO())
-		if err != nil {
-			return fmt.Errorf("WaitForResult(%s): %w", id, err)
+func (c *commandSubmit) LogAnalysis(analysis *vone.Analysis) {
+	id := analysis.ID
+	if analysis.Quota != nil {
+		c.LogQuota(id, analysis.Quota)
+	}
+	if analysis.Digest.SHA256 != "" {
+		log.Printf("%s Uploaded SHA256: %s", id, analysis.Digest.SHA256)
+	}
+	if analysis.Result != nil {
+		results := analysis.Result
+		log.Printf("%s Type: %s", id, results.Type)
+		log.Printf("%s TrueFileType: %s", id, results.TrueFileType)
+		log.Printf("%s RiskLevel: %s", id, results.RiskLevel)
+		if len(results.DetectionNames) > 0 {
+			log.Printf("%s DetectionNames: %s", id, strings.Join(results.DetectionNames, ", "))
 		}
-		//if status.ResourceLocation == "" {
-		//	return fmt.Errorf("%s: %w", id, ErrUnsupportedFileType)
-		//}
-		//		log.Printf("XXX %s status struct: %v", id, status)
-		//		log.Printf("XXX %s Action: %v", id, status.Action)
-		//		log.Printf("XXX %s ResourceLocation: %v", id, status.ResourceLocation)
-		//		log.Printf("XXX %s Error: %v", id, status.Error)
-		//		log.Printf("XXX %s IsCached: %v", id, status.IsCached)
-		//		log.Printf("XXX %s Digest: %v", id, status.Digest)
-		//		log.Printf("XXX %s Arguments: %v", id, status.Arguments)
-		//		log.Printf("XXX %s lastActionDateTime: %v", id, status.LastActionDateTime)
-		log.Printf("%s Status: %v", id, status.Status)
-		switch status.Status {
-		case vone.StatusSucceeded:
-			return nil
-		case vone.StatusRunning:
-			if time.Now().After(endTime) {
-				return ErrTimeout
-			}
-			time.Sleep(5 * time.Second)
-		case vone.StatusFailed:
-			return fmt.Errorf("%s: %s", status.Error.Code, status.Error.Message)
-		default:
-			return fmt.Errorf("unknown status: %s", status.Status)
+		if len(results.ThreatTypes) > 0 {
+			log.Printf("%s ThreatTypes: %s", id, strings.Join(results.ThreatTypes, ", "))
 		}
 	}
-}
-
-func (c *commandSubmit) AnalysisResult(id string) (bool, error) {
-	log.Printf("SandboxAnalysisResults(%s)", id)
-	results, err := c.visionOne.SandboxAnalysisResults(id).Do(context.TODO())
-	if err != nil {
-		return false, err
+	so := &vone.SandboxSuspiciousObjectsResponse{Items: analysis.SuspiciousObjects}
+	for _, sha1 := range ListSHA1(so) {
+		log.Printf("%s Suspicious Object SHA1: %s", id, sha1)
+	}
+	for _, ip := range ListIP(so) {
+		log.Printf("%s Suspicious Object IP: %s", id, ip)
 	}
-	log.Printf("%s Type: %s", id, results.Type)
-	log.Printf("%s TrueFileType: %s", id, results.TrueFileType)
-	log.Printf("%s RiskLevel: %s", id, results.RiskLevel)
-	if len(results.DetectionNames) > 0 {
-		log.Printf("%s DetectionNames: %s", id, strings.Join(results.DetectionNames, ", "))
+	if analysis.ReportPath != "" {
+		log.Printf("%s PDF report saved: %s", id, analysis.ReportPath)
 	}
-	if len(results.ThreatTypes) > 0 {
-		log.Printf("%s ThreatTypes: %s", id, strings.Join(results.ThreatTypes, ", "))
+	if analysis.InvestigationPackagePath != "" {
+		log.Printf("%s Investigation Package Saved: %s", id, analysis.InvestigationPackagePath)
+	}
+}
+
+func (c *commandSubmit) LogQuota(id string, headers *vone.SandboxSubmitFileResponseHeaders) {
+	log.Printf("%s Daily quota: %d", id, headers.SubmissionReserveCount)
+	note := ""
+	if headers.SubmissionRemainingCount == 0 {
+		note = " (consider to add Credits to sandbox feature)"
 	}
-	return results.RiskLevel != vone.RiskLevelNoRisk, nil //"high", nil
+	log.Printf("%s Quota left: %d%s", id, headers.SubmissionRemainingCount, note)
+	log.Printf("%s Today submissions: %d", id, headers.SubmissionCount)
+	log.Printf("%s Today submissions of unsupported files: %d (not accounted in quota)", id, headers.SubmissionExemptionCount)
 }
 
 func ListSHA1(so *vone.SandboxSuspiciousObjectsResponse) (result []string) {
@@ -291,60 +308,16 @@ func ListIP(so *vone.SandboxSuspiciousObjectsResponse) (result []string) {
 	return
 }
 
-func (c *commandSubmit) SuspiciousObjects(id string) error {
-	log.Printf("%s Request Suspicious Objects", id)
-	so, err := c.visionOne.SandboxSuspiciousObjects(id).Do(context.TODO())
-	if err != nil {
-		return err
-	}
-	soSHA1 := ListSHA1(so)
-	for _, sha1 := range soSHA1 {
-		log.Printf("%s Suspicious Object SHA1: %s", id, sha1)
-	}
-	soIP := ListIP(so)
-	for _, ip := range soIP {
-		log.Printf("%s Suspicious Object IP: %s", id, ip)
-	}
-	return nil
-}
-
-func (c *commandSubmit) PDFReport(id string) error {
-	log.Printf("%s Download report PDF", id)
-	pdfFileName := id + ".pdf"
-	for {
-		log.Println("Download PDF")
-		err := c.visionOne.SandboxDownloadResults(id).Store(context.TODO(), pdfFileName)
-		if err != nil {
-			var vOneErr *vone.Error
-			if !errors.As(err, &vOneErr) {
-				return err
-			}
-			if vOneErr.Code == vone.ErrorCodeNotFound {
-				continue
-			}
-			return err
-		}
-		break
-	}
-	log.Printf("%s PDF report saved: %s", id, pdfFileName)
-	return nil
-}
-
-func (c *commandSubmit) InvestigationPackage(id string) error {
-	log.Printf("%s Download Investigation Package", id)
-	zipFileName := id + ".zip"
-	if err := c.visionOne.SandboxInvestigationPackage(id).Store(context.TODO(), zipFileName); err != nil {
-		return err
-	}
-	log.Printf("%s Investigation Package Saved: %s", id, zipFileName)
-	return nil
-}
-
 func (c *commandSubmit) Setup(name string) {
 	c.baseCommand.Setup(name, "Upload file/URL to sandbox and download analysis result")
 	c.fs.String(flagFileName, "", "Sample file path")
 	c.fs.String(flagMask, "", "Sample files mask")
 	c.fs.String(flagURL, "", "Sample URL")
 	c.fs.String(flagURLsFile, "", "File with URLs")
+	c.fs.Int(flagConcurrency, 4, "Maximum amount of simultaneous URL submissions and analyses")
+	c.fs.String(flagArchivePassword, "", "Password of submitted archives")
+	c.fs.String(flagDocumentPassword, "", "Password of submitted documents")
+	c.fs.Bool(flagExpand, false, "Extract zip and tar.gz archives locally and submit their files one by one")
+	c.fs.String(flagSTIX, "", "Folder to save STIX 2.1 bundle of each analysis to")
 	c.fs.Duration(flagTimeout, 10*time.Minute, "Analysis timeout")
 }
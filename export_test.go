@@ -0,0 +1,7 @@
+package vone
+
+// Unexported functions used by package vone_test tests
+var (
+	ParseRetryAfter = parseRetryAfter
+	ResolveLocation = (*VOne).resolveLocation
+)
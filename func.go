@@ -22,6 +22,7 @@ var ErrAlreadyCalled = errors.New("Do() or Store() already called")
 type vOneRequest interface {
 	method() string                // GET, POST, ...
 	url() string                   // last part of URI
+	operation() string             // name of VOne method creating request, like "SandboxSubmitFile"
 	uri() string                   // full URI (with https://xdr...)
 	requestBody() io.Reader        // Body
 	populateHeaders(*http.Request) // Populate request headers
@@ -29,7 +30,7 @@ type vOneRequest interface {
 	contentType() string           // application/json by default
 	responseStruct() any           // Pointer to struct/slice to parse JSON
 	responseHeader() any           // Return struct to populate with response headers
-	responseBody(io.ReadCloser)    // process body - is called only if responseStruct returns any
+	responseBody(io.ReadCloser)    // process body - is called only if responseStruct returns nil. Should close body
 }
 
 var _ vOneRequest = &baseRequest{}
@@ -49,6 +50,10 @@ func (f *baseRequest) url() string {
 	return ""
 }
 
+func (f *baseRequest) operation() string {
+	return ""
+}
+
 func (f *baseRequest) uri() string {
 	return ""
 }
@@ -65,7 +70,12 @@ func (f *baseRequest) responseStruct() any {
 	return nil
 }
 
-func (f *baseRequest) responseBody(io.ReadCloser) {
+func (f *baseRequest) responseBody(body io.ReadCloser) {
+	body.Close()
+}
+
+func (f *baseRequest) client() *VOne {
+	return f.vone
 }
 
 func (f *baseRequest) init(vone *VOne) {
@@ -82,6 +92,18 @@ func (f *baseRequest) setParameter(name, value string) {
 	f.parameters[name] = value
 }
 
+// query - return request headers and parameters identifying the query
+func (f *baseRequest) query() map[string]string {
+	result := make(map[string]string, len(f.headers)+len(f.parameters))
+	for key, value := range f.headers {
+		result["header:"+key] = value
+	}
+	for key, value := range f.parameters {
+		result["parameter:"+key] = value
+	}
+	return result
+}
+
 func (f *baseRequest) populateHeaders(req *http.Request) {
 	for key, value := range f.headers {
 		req.Header.Add(key, value)
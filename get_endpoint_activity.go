@@ -150,6 +150,10 @@ func (f *getEndpointActivityRequest) url() string {
 	return "/v3.0/search/endpointActivities"
 }
 
+func (f *getEndpointActivityRequest) operation() string {
+	return "GetEndpointActivity"
+}
+
 func (f *getEndpointActivityRequest) uri() string {
 	return f.response.NextLink
 }
@@ -167,6 +171,10 @@ func (f *getEndpointActivityRequest) resetPagination() {
 	f.response.NextLink = ""
 }
 
+func (f *getEndpointActivityRequest) setNextLink(link string) {
+	f.response.NextLink = link
+}
+
 func (f *getEndpointActivityRequest) Paginator() *Paginator[
 	GetEndpointActivityResponse,
 	GetEndpointActivityResponseItem,
@@ -209,19 +209,22 @@ func (f *getEndPointListRequest) resetPagination() {
 	f.response.NextLink = ""
 }
 
-/*
+func (f *getEndPointListRequest) setNextLink(link string) {
+	f.response.NextLink = link
+}
+
 func (s *getEndPointListRequest) uri() string {
-	if s.response.NextLink != "" {
-		return s.response.NextLink
-	}
-	return ""
+	return s.response.NextLink
 }
-*/
 
 func (s *getEndPointListRequest) url() string {
 	return "/v3.0/endpointSecurity/endpoints"
 }
 
+func (s *getEndPointListRequest) operation() string {
+	return "EndPointList"
+}
+
 func (f *getEndPointListRequest) responseStruct() any {
 	return &f.response
 }
@@ -142,6 +142,10 @@ func (f *getMobileActivityRequest) url() string {
 	return "/v3.0/search/mobileActivities"
 }
 
+func (f *getMobileActivityRequest) operation() string {
+	return "GetMobileActivity"
+}
+
 func (f *getMobileActivityRequest) uri() string {
 	return f.response.NextLink
 }
@@ -159,6 +163,10 @@ func (f *getMobileActivityRequest) resetPagination() {
 	f.response.NextLink = ""
 }
 
+func (f *getMobileActivityRequest) setNextLink(link string) {
+	f.response.NextLink = link
+}
+
 func (f *getMobileActivityRequest) Paginator() *Paginator[
 	GetMobileActivityResponse,
 	GetMobileActivityResponseItem,
@@ -156,6 +156,10 @@ func (f *getNetworkActivityRequest) url() string {
 	return "/v3.0/search/networkActivities"
 }
 
+func (f *getNetworkActivityRequest) operation() string {
+	return "GetNetworkActivity"
+}
+
 func (f *getNetworkActivityRequest) uri() string {
 	return f.response.NextLink
 }
@@ -173,6 +177,10 @@ func (f *getNetworkActivityRequest) resetPagination() {
 	f.response.NextLink = ""
 }
 
+func (f *getNetworkActivityRequest) setNextLink(link string) {
+	f.response.NextLink = link
+}
+
 func (f *getNetworkActivityRequest) Paginator() *Paginator[
 	GetNetworkActivityResponse,
 	GetNetworkActivityResponseItem,
@@ -726,6 +726,10 @@ func (s *GetOATEventsRequest) url() string {
 	return "/v3.0/oat/detections"
 }
 
+func (s *GetOATEventsRequest) operation() string {
+	return "GetOATEvents"
+}
+
 func (f *GetOATEventsRequest) responseStruct() any {
 	return &f.response
 }
@@ -742,6 +746,10 @@ func (f *GetOATEventsRequest) resetPagination() {
 	f.response.NextLink = ""
 }
 
+func (f *GetOATEventsRequest) setNextLink(link string) {
+	f.response.NextLink = link
+}
+
 func (f *GetOATEventsRequest) Next(ctx context.Context) (*ObservedAttackTechniquesEventsResponse, error) {
 	if f.response.NextLink == "" {
 		return nil, io.EOF
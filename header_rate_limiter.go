@@ -0,0 +1,272 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	header_rate_limiter.go - token bucket rate limiter driven by RateLimit-* headers
+*/
+
+package vone
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointRateLimiter - RateLimiter that is informed about each request and response.
+// VOne calls Wait before sending request and Observe for each received response
+type EndpointRateLimiter interface {
+	RateLimiter
+	Wait(req *http.Request) error
+	Observe(req *http.Request, resp *http.Response)
+}
+
+// DefaultMaxThrottles - amount of consecutive 429 responses HeaderRateLimiter retries
+const DefaultMaxThrottles = 10
+
+// defaultThrottleDelay - pause after 429 response without Retry-After or RateLimit-Reset header
+const defaultThrottleDelay = time.Second
+
+// RateLimiterStats - statistics of HeaderRateLimiter for one endpoint family
+type RateLimiterStats struct {
+	Requests  int           // Requests sent
+	Waits     int           // Requests that were delayed
+	WaitTime  time.Duration // Total delay
+	Throttles int           // Responses with 429 status
+}
+
+// EndpointFamily - return API group of the request path: "sandbox" for
+// /v3.0/sandbox/files/analyze, "workbench" for /v3.0/workbench/alerts etc.
+func EndpointFamily(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(segments) > 1 && strings.HasPrefix(segments[0], "v") {
+		return segments[1]
+	}
+	return segments[0]
+}
+
+// throttlePause - time to wait after 429 response
+func throttlePause(header http.Header) time.Duration {
+	pause := time.Duration(parseInt(header.Get("RateLimit-Reset"))) * time.Second
+	if retryAfter := parseInt(header.Get("Retry-After")); retryAfter > 0 {
+		pause = max(pause, time.Duration(retryAfter)*time.Second)
+	}
+	if pause <= 0 {
+		pause = defaultThrottleDelay
+	}
+	return pause
+}
+
+// tokenBucket - state of one endpoint family
+type tokenBucket struct {
+	known     bool // limit and window were received from Vision One
+	limit     int
+	window    time.Duration
+	tokens    float64
+	updated   time.Time
+	notBefore time.Time
+	throttles int // consecutive 429 responses retried
+	stats     RateLimiterStats
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if !b.known {
+		return
+	}
+	elapsed := now.Sub(b.updated)
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit), b.tokens+elapsed.Seconds()*b.rate())
+	}
+	b.updated = now
+}
+
+// rate - tokens per second
+func (b *tokenBucket) rate() float64 {
+	return float64(b.limit) / b.window.Seconds()
+}
+
+// reserve - take token and return time to wait before request can be sent
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.stats.Requests++
+	var wait time.Duration
+	if b.known {
+		b.refill(now)
+		b.tokens--
+		if b.tokens < 0 {
+			wait = time.Duration(-b.tokens / b.rate() * float64(time.Second))
+		}
+	}
+	if pause := b.notBefore.Sub(now); pause > wait {
+		wait = pause
+	}
+	if wait > 0 {
+		b.stats.Waits++
+		b.stats.WaitTime += wait
+	}
+	return wait
+}
+
+func (b *tokenBucket) observe(now time.Time, status int, header http.Header) {
+	limit := parseInt(header.Get("RateLimit-Limit"))
+	window := parseInt(header.Get("RateLimit-Window"))
+	reset := time.Duration(parseInt(header.Get("RateLimit-Reset"))) * time.Second
+	remaining, err := strconv.Atoi(header.Get("RateLimit-Remaining"))
+	if limit > 0 && window > 0 && err == nil {
+		b.refill(now)
+		if !b.known {
+			b.tokens = float64(remaining)
+		}
+		b.known = true
+		b.limit = limit
+		b.window = time.Duration(window) * time.Second
+		b.updated = now
+		// other requests can be in flight, so only lower local estimate
+		b.tokens = math.Min(b.tokens, float64(remaining))
+		if remaining == 0 && reset > 0 {
+			b.notBefore = now.Add(reset)
+		}
+	}
+	if status != http.StatusTooManyRequests {
+		b.throttles = 0
+		return
+	}
+	b.stats.Throttles++
+	pause := throttlePause(header)
+	if b.notBefore.Before(now.Add(pause)) {
+		b.notBefore = now.Add(pause)
+	}
+	if b.known {
+		b.tokens = math.Min(b.tokens, 0)
+	}
+}
+
+// HeaderRateLimiter - rate limiter that keeps token bucket for each endpoint
+// family. Buckets are updated from RateLimit-Limit, RateLimit-Window,
+// RateLimit-Remaining and RateLimit-Reset headers of every response, so
+// requests are delayed before Vision One starts responding with 429 status.
+// If 429 is received anyway, request is sent again after RateLimit-Reset
+type HeaderRateLimiter struct {
+	// MaxThrottles - amount of consecutive 429 responses to retry
+	MaxThrottles int
+
+	stop    chan struct{}
+	family  func(*http.Request) string
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+var _ EndpointRateLimiter = &HeaderRateLimiter{}
+
+// NewHeaderRateLimiter - create rate limiter. Closing stop channel aborts waiting requests.
+// stop can be nil
+func NewHeaderRateLimiter(stop chan struct{}) *HeaderRateLimiter {
+	return &HeaderRateLimiter{
+		MaxThrottles: DefaultMaxThrottles,
+		stop:         stop,
+		family:       EndpointFamily,
+		now:          time.Now,
+		buckets:      make(map[string]*tokenBucket),
+	}
+}
+
+// SetFamily - set function grouping requests sharing the same rate limit.
+// Default is EndpointFamily
+func (l *HeaderRateLimiter) SetFamily(family func(*http.Request) string) *HeaderRateLimiter {
+	l.family = family
+	return l
+}
+
+// bucket - should be called with mu locked
+func (l *HeaderRateLimiter) bucket(family string) *tokenBucket {
+	b, ok := l.buckets[family]
+	if !ok {
+		b = &tokenBucket{}
+		l.buckets[family] = b
+	}
+	return b
+}
+
+// ShouldAbort - check whether stop channel is closed
+func (l *HeaderRateLimiter) ShouldAbort() bool {
+	select {
+	case <-l.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// CheckError - request to send request again if it was throttled. Consecutive
+// throttles are counted for each endpoint family separately
+func (l *HeaderRateLimiter) CheckError(err error) error {
+	rateLimitErr, ok := IsRateLimit(err)
+	if !ok {
+		return err
+	}
+	family := ""
+	if rateLimitErr.request != nil {
+		family = l.family(rateLimitErr.request)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return checkThrottle(err, &l.bucket(family).throttles, l.MaxThrottles)
+}
+
+// checkThrottle - return ErrOnceMore for up to maxThrottles consecutive rate limit errors
+func checkThrottle(err error, throttles *int, maxThrottles int) error {
+	if _, ok := IsRateLimit(err); !ok {
+		*throttles = 0
+		return err
+	}
+	*throttles++
+	if *throttles > maxThrottles {
+		*throttles = 0
+		return err
+	}
+	return ErrOnceMore
+}
+
+// Wait - block until request can be sent without surpassing rate limit
+func (l *HeaderRateLimiter) Wait(req *http.Request) error {
+	l.mu.Lock()
+	wait := l.bucket(l.family(req)).reserve(l.now())
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	return sleep(req.Context(), wait, l.stop)
+}
+
+// Observe - update token bucket using response headers
+func (l *HeaderRateLimiter) Observe(req *http.Request, resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.bucket(l.family(req)).observe(l.now(), resp.StatusCode, resp.Header)
+}
+
+// Stats - return statistics for each endpoint family
+func (l *HeaderRateLimiter) Stats() map[string]RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := make(map[string]RateLimiterStats, len(l.buckets))
+	for family, b := range l.buckets {
+		result[family] = b.stats
+	}
+	return result
+}
+
+// TotalStats - return statistics summed for all endpoint families
+func (l *HeaderRateLimiter) TotalStats() RateLimiterStats {
+	var total RateLimiterStats
+	for _, s := range l.Stats() {
+		total.Requests += s.Requests
+		total.Waits += s.Waits
+		total.WaitTime += s.WaitTime
+		total.Throttles += s.Throttles
+	}
+	return total
+}
@@ -0,0 +1,141 @@
+package vone
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeaderRateLimiterBucket(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewHeaderRateLimiter(nil)
+	l.now = func() time.Time { return now }
+	req := &http.Request{URL: &url.URL{Path: "/v3.0/sandbox/files/analyze"}}
+	req = req.WithContext(context.Background())
+	response := func(status int, remaining, reset string) *http.Response {
+		header := make(http.Header)
+		header.Set("RateLimit-Limit", "10")
+		header.Set("RateLimit-Window", "10")
+		header.Set("RateLimit-Remaining", remaining)
+		header.Set("RateLimit-Reset", reset)
+		return &http.Response{StatusCode: status, Header: header}
+	}
+	wait := func() time.Duration {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.bucket(l.family(req)).reserve(now)
+	}
+	if w := wait(); w != 0 {
+		t.Fatalf("unknown limit: expected no wait, got %v", w)
+	}
+	l.Observe(req, response(http.StatusOK, "2", "5"))
+	if w := wait(); w != 0 {
+		t.Errorf("expected no wait, got %v", w)
+	}
+	if w := wait(); w != 0 {
+		t.Errorf("expected no wait, got %v", w)
+	}
+	if w := wait(); w != time.Second {
+		t.Errorf("expected 1s wait, got %v", w)
+	}
+	now = now.Add(time.Second)
+	l.Observe(req, response(http.StatusOK, "0", "4"))
+	if w := wait(); w != 4*time.Second {
+		t.Errorf("expected 4s wait, got %v", w)
+	}
+	l.Observe(req, response(http.StatusTooManyRequests, "0", "7"))
+	if w := wait(); w != 7*time.Second {
+		t.Errorf("expected 7s wait, got %v", w)
+	}
+	other := &http.Request{URL: &url.URL{Path: "/v3.0/workbench/alerts"}}
+	l.Observe(other, &http.Response{StatusCode: http.StatusOK, Header: make(http.Header)})
+
+	stats := l.Stats()
+	if s := stats["sandbox"]; s.Requests != 6 || s.Waits != 3 || s.Throttles != 1 || s.WaitTime != 12*time.Second {
+		t.Errorf("unexpected sandbox stats: %+v", s)
+	}
+	if _, ok := stats["workbench"]; !ok {
+		t.Errorf("workbench family is missing: %v", stats)
+	}
+	if total := l.TotalStats(); total.Throttles != 1 {
+		t.Errorf("unexpected total stats: %+v", total)
+	}
+}
+
+func TestHeaderRateLimiterCheckError(t *testing.T) {
+	l := NewHeaderRateLimiter(nil)
+	l.MaxThrottles = 2
+	rateLimitErr := &RateLimitError{Status: http.StatusTooManyRequests}
+	for i := 0; i < 2; i++ {
+		if err := l.CheckError(rateLimitErr); err != ErrOnceMore {
+			t.Fatalf("expected ErrOnceMore, got %v", err)
+		}
+	}
+	if err := l.CheckError(rateLimitErr); err != rateLimitErr {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+	if err := l.CheckError(nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	sandbox := &http.Request{URL: &url.URL{Path: "/v3.0/sandbox/files/analyze"}}
+	workbench := &http.Request{URL: &url.URL{Path: "/v3.0/workbench/alerts"}}
+	sandboxErr := &RateLimitError{Status: http.StatusTooManyRequests, request: sandbox}
+	workbenchErr := &RateLimitError{Status: http.StatusTooManyRequests, request: workbench}
+	for i := 0; i < 2; i++ {
+		if err := l.CheckError(sandboxErr); err != ErrOnceMore {
+			t.Fatalf("sandbox: expected ErrOnceMore, got %v", err)
+		}
+	}
+	// throttles of other family neither use up nor reset sandbox ones
+	if err := l.CheckError(workbenchErr); err != ErrOnceMore {
+		t.Errorf("workbench: expected ErrOnceMore, got %v", err)
+	}
+	l.Observe(workbench, &http.Response{StatusCode: http.StatusOK, Header: make(http.Header)})
+	if err := l.CheckError(sandboxErr); err != sandboxErr {
+		t.Errorf("sandbox: expected rate limit error, got %v", err)
+	}
+	l.Observe(sandbox, &http.Response{StatusCode: http.StatusOK, Header: make(http.Header)})
+	if err := l.CheckError(sandboxErr); err != ErrOnceMore {
+		t.Errorf("sandbox: expected ErrOnceMore after success, got %v", err)
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if !NewHeaderRateLimiter(stop).ShouldAbort() {
+		t.Errorf("expected abort after stop")
+	}
+}
+
+// failingRateLimiter - limiter that refuses every request
+type failingRateLimiter struct {
+	*HeaderRateLimiter
+}
+
+func (failingRateLimiter) Wait(*http.Request) error {
+	return ErrStop
+}
+
+func TestHeaderRateLimiterWaitErrorClosesBody(t *testing.T) {
+	v1 := newTestVOne(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	}))
+	v1.SetRateLimiter(failingRateLimiter{NewHeaderRateLimiter(nil)})
+	ctx := context.Background()
+	submit := v1.SandboxSubmitFile()
+	if err := submit.SetReader(ctx, strings.NewReader(strings.Repeat("x", 1<<20)), "sample.exe"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := submit.Do(ctx); !errors.Is(err, ErrStop) {
+		t.Errorf("expected ErrStop, got %v", err)
+	}
+	select {
+	case <-submit.uploaded:
+	case <-time.After(5 * time.Second):
+		t.Error("upload body is not released")
+	}
+}
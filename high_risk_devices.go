@@ -114,6 +114,10 @@ func (s *HighRiskDevicesRequest) url() string {
 	return "/v3.0/asrm/highRiskDevices"
 }
 
+func (s *HighRiskDevicesRequest) operation() string {
+	return "HighRiskDevices"
+}
+
 func (f *HighRiskDevicesRequest) responseStruct() any {
 	return &f.response
 }
@@ -0,0 +1,298 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Sandbox API capabilities
+
+	investigation_package.go - parse contents of investigation package
+*/
+
+package vone
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// PackageEntryKind - kind of investigation package file
+type PackageEntryKind string
+
+const (
+	PackageEntryDropped    PackageEntryKind = "dropped"
+	PackageEntryPCAP       PackageEntryKind = "pcap"
+	PackageEntryScreenshot PackageEntryKind = "screenshot"
+	PackageEntryReport     PackageEntryKind = "report"
+	PackageEntryOther      PackageEntryKind = "other"
+)
+
+// DroppedFolders - names (case insensitive) of investigation package folders
+// with files dropped by sample. Package layout is not documented by Vision One
+// API, so classification assumes following layout (also served by vonetest):
+//
+//	report.json, *.xml    analysis reports
+//	dropped/<file>        files dropped by sample
+//	network/*.pcap        captured network traffic
+//	screenshot/*.png      screenshots of analysis environment
+//	<other>               files of unknown kind
+//
+// Layout can be nested into any folder. Only dropped files are detected
+// by folder; other kinds are detected by extension anywhere in the package.
+// Add folder names here if packages of your tenant use other ones
+var DroppedFolders = []string{"dropped"}
+
+// PackageEntry - file of investigation package
+type PackageEntry struct {
+	Name string           // Path inside of package
+	Kind PackageEntryKind // Kind detected by folder and extension. See DroppedFolders
+	Size int64            // Uncompressed size
+	// Digest - hashes of dropped file. Empty for other kinds or if Err is set
+	Digest Digest
+	// Err - error of reading dropped file (like too big or encrypted with
+	// other password). Other package files are available anyway
+	Err error
+	// SuspiciousObject - suspicious object with SHA1 of dropped file. Set by CrossReference
+	SuspiciousObject *SandboxSuspiciousObject
+
+	file     *zip.File
+	password string
+}
+
+// Open - return reader of entry content
+func (e *PackageEntry) Open() (io.ReadCloser, error) {
+	reader, err := openZipFile(e.file, e.password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.Name, err)
+	}
+	return reader, nil
+}
+
+// Bytes - return entry content
+func (e *PackageEntry) Bytes() ([]byte, error) {
+	reader, err := e.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.Name, err)
+	}
+	return data, nil
+}
+
+// Decode - unmarshal JSON or XML report into v depending on entry extension
+func (e *PackageEntry) Decode(v any) error {
+	data, err := e.Bytes()
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(path.Ext(e.Name), ".xml") {
+		err = xml.Unmarshal(data, v)
+	} else {
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", e.Name, err)
+	}
+	return nil
+}
+
+// InvestigationPackage - contents of investigation package grouped by kind. Usage:
+//
+//	pkg, err := v1.SandboxInvestigationPackage(id).Open(ctx, "")
+//	...
+//	for _, dropped := range pkg.CrossReference(suspiciousObjects) {
+//		fmt.Println(dropped.Name, dropped.SuspiciousObject.RiskLevel)
+//	}
+type InvestigationPackage struct {
+	Dropped     []*PackageEntry // Files dropped by sample
+	PCAPs       []*PackageEntry // Captured network traffic
+	Screenshots []*PackageEntry // Screenshots of analysis environment
+	Reports     []*PackageEntry // XML and JSON reports
+	Other       []*PackageEntry // Files of unknown kind
+
+	closer io.Closer
+}
+
+// OpenInvestigationPackage - open investigation package stored on disk. Password
+// is used for encrypted package files and can be empty. Package should be closed
+// after use
+func OpenInvestigationPackage(packagePath, password string) (*InvestigationPackage, error) {
+	r, err := zip.OpenReader(packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", packagePath, err)
+	}
+	p, err := newInvestigationPackage(&r.Reader, password)
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("%s: %w", packagePath, err)
+	}
+	p.closer = r
+	return p, nil
+}
+
+// ReadInvestigationPackage - parse investigation package of given size
+// from memory or other random access source
+func ReadInvestigationPackage(r io.ReaderAt, size int64, password string) (*InvestigationPackage, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("investigation package: %w", err)
+	}
+	return newInvestigationPackage(zr, password)
+}
+
+// MaxInvestigationPackageSize - maximum size of investigation package
+// downloaded into memory by Open
+var MaxInvestigationPackageSize int64 = 1 << 30
+
+// newInvestigationPackage - classify package files and hash dropped ones
+func newInvestigationPackage(r *zip.Reader, password string) (*InvestigationPackage, error) {
+	p := &InvestigationPackage{}
+	limit := DefaultArchiveLimits().MaxMemberSize
+	for _, f := range r.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		entry := &PackageEntry{
+			Name:     f.Name,
+			Kind:     packageEntryKind(f.Name),
+			Size:     int64(f.UncompressedSize64),
+			file:     f,
+			password: password,
+		}
+		switch entry.Kind {
+		case PackageEntryDropped:
+			entry.Err = entry.hash(limit)
+			p.Dropped = append(p.Dropped, entry)
+		case PackageEntryPCAP:
+			p.PCAPs = append(p.PCAPs, entry)
+		case PackageEntryScreenshot:
+			p.Screenshots = append(p.Screenshots, entry)
+		case PackageEntryReport:
+			p.Reports = append(p.Reports, entry)
+		default:
+			p.Other = append(p.Other, entry)
+		}
+	}
+	return p, nil
+}
+
+// hash - calculate digest of entry not reading more than limit bytes
+func (e *PackageEntry) hash(limit int64) error {
+	if e.Size > limit {
+		return fmt.Errorf("%w: %s: file size %d is more than %d", ErrArchiveLimit, e.Name, e.Size, limit)
+	}
+	reader, err := e.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	hasher := newDigestHash()
+	size, err := io.Copy(hasher, io.LimitReader(reader, limit+1))
+	if err != nil {
+		return fmt.Errorf("%s: %w", e.Name, err)
+	}
+	if size > limit {
+		return fmt.Errorf("%w: %s: file is bigger than %d", ErrArchiveLimit, e.Name, limit)
+	}
+	e.Size = size
+	e.Digest = hasher.Digest()
+	return nil
+}
+
+// packageEntryKind - detect kind of file by its folder and extension
+func packageEntryKind(name string) PackageEntryKind {
+	name = strings.ToLower(strings.ReplaceAll(name, "\\", "/"))
+	folders := strings.Split(path.Dir(name), "/")
+	if slices.ContainsFunc(folders, func(folder string) bool {
+		return slices.ContainsFunc(DroppedFolders, func(dropped string) bool {
+			return strings.EqualFold(dropped, folder)
+		})
+	}) {
+		return PackageEntryDropped
+	}
+	switch path.Ext(name) {
+	case ".pcap", ".pcapng", ".cap":
+		return PackageEntryPCAP
+	case ".png", ".jpg", ".jpeg", ".bmp", ".gif":
+		return PackageEntryScreenshot
+	case ".xml", ".json":
+		return PackageEntryReport
+	default:
+		return PackageEntryOther
+	}
+}
+
+// Entries - return all package files
+func (p *InvestigationPackage) Entries() []*PackageEntry {
+	return slices.Concat(p.Dropped, p.PCAPs, p.Screenshots, p.Reports, p.Other)
+}
+
+// CrossReference - set SuspiciousObject of dropped files which SHA1 is in the
+// list of suspicious objects. Return matched dropped files
+func (p *InvestigationPackage) CrossReference(suspiciousObjects []SandboxSuspiciousObject) []*PackageEntry {
+	var matched []*PackageEntry
+	for _, entry := range p.Dropped {
+		entry.SuspiciousObject = nil
+		for i := range suspiciousObjects {
+			if suspiciousObjects[i].FileSHA1 != "" && strings.EqualFold(suspiciousObjects[i].FileSHA1, entry.Digest.SHA1) {
+				entry.SuspiciousObject = &suspiciousObjects[i]
+				matched = append(matched, entry)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// InvestigationPackage - open downloaded investigation package with dropped
+// files cross-referenced with suspicious objects. Package should be closed after use
+func (a *Analysis) InvestigationPackage(password string) (*InvestigationPackage, error) {
+	if a.InvestigationPackagePath == "" {
+		return nil, fmt.Errorf("%s: investigation package is not downloaded", a.Object)
+	}
+	p, err := OpenInvestigationPackage(a.InvestigationPackagePath, password)
+	if err != nil {
+		return nil, err
+	}
+	p.CrossReference(a.SuspiciousObjects)
+	return p, nil
+}
+
+// Close - close package file. Does nothing for packages read from memory
+func (p *InvestigationPackage) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+	return p.closer.Close()
+}
+
+// Open - download investigation package into memory and parse it. Password
+// is used for encrypted package files and can be empty.
+// Only call Do(), Store() or Open() once
+func (s *sandboxInvestigationPackageRequest) Open(ctx context.Context, password string) (*InvestigationPackage, error) {
+	body, err := s.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, fmt.Errorf("investigation package: %w", io.ErrUnexpectedEOF)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, MaxInvestigationPackageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("investigation package: %w", err)
+	}
+	if int64(len(data)) > MaxInvestigationPackageSize {
+		return nil, fmt.Errorf("investigation package: %w: size is more than %d", ErrArchiveLimit, MaxInvestigationPackageSize)
+	}
+	return ReadInvestigationPackage(bytes.NewReader(data), int64(len(data)), password)
+}
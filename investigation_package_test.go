@@ -0,0 +1,131 @@
+package vone
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPackageEntryKind(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected PackageEntryKind
+	}{
+		{"report.json", PackageEntryReport},
+		{"dropped/payload.exe", PackageEntryDropped},
+		{"dropped/screen.png", PackageEntryDropped},
+		{"network/traffic.pcap", PackageEntryPCAP},
+		{"screenshot/1.png", PackageEntryScreenshot},
+		{"analysis/environment.txt", PackageEntryOther},
+		{"abc/Dropped/payload.exe", PackageEntryDropped},
+		{"abc\\dropped\\payload.exe", PackageEntryDropped},
+		{"abc/network/traffic.PCAP", PackageEntryPCAP},
+		{"report/report.xml", PackageEntryReport},
+		{"drop/payload.exe", PackageEntryOther},
+		{"dropped.txt", PackageEntryOther},
+	}
+	for _, tc := range testCases {
+		if actual := packageEntryKind(tc.name); actual != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, actual)
+		}
+	}
+	saved := DroppedFolders
+	t.Cleanup(func() { DroppedFolders = saved })
+	DroppedFolders = append(slices.Clone(saved), "DropFiles")
+	if actual := packageEntryKind("abc/dropfiles/payload.exe"); actual != PackageEntryDropped {
+		t.Errorf("expected dropped by added folder, got %s", actual)
+	}
+}
+
+func TestOpenInvestigationPackage(t *testing.T) {
+	files := map[string][]byte{
+		"sample/dropped/payload.exe": []byte("payload content"),
+		"sample/dropped/readme.txt":  []byte("readme content"),
+		"sample/traffic.pcap":        []byte("pcap content"),
+		"sample/screenshot/1.png":    []byte("png content"),
+		"sample/report.xml":          []byte(`<report><riskLevel>high</riskLevel></report>`),
+		"sample/report.json":         []byte(`{"riskLevel":"high"}`),
+		"sample/other.log":           []byte("log content"),
+	}
+	suspiciousObjects := []SandboxSuspiciousObject{
+		{Domain: "example.com"},
+		{RiskLevel: RiskLevelHigh, FileSHA1: strings.ToUpper(sha1Hex(files["sample/dropped/payload.exe"]))},
+	}
+	dir := t.TempDir()
+	for _, password := range []string{"", "virus"} {
+		packagePath := filepath.Join(dir, "package"+password+".zip")
+		writeTestZip(t, packagePath, files, password)
+		p, err := OpenInvestigationPackage(packagePath, password)
+		if err != nil {
+			t.Fatalf("%q: %v", password, err)
+		}
+		if len(p.Dropped) != 2 || len(p.PCAPs) != 1 || len(p.Screenshots) != 1 || len(p.Reports) != 2 || len(p.Other) != 1 {
+			t.Errorf("%q: wrong classification: %+v", password, p)
+		}
+		if len(p.Entries()) != len(files) {
+			t.Errorf("%q: expected %d entries, got %d", password, len(files), len(p.Entries()))
+		}
+		for _, entry := range p.Dropped {
+			if entry.Digest.SHA1 != sha1Hex(files[entry.Name]) {
+				t.Errorf("%q: %s: wrong digest: %v", password, entry.Name, entry.Digest)
+			}
+		}
+		matched := p.CrossReference(suspiciousObjects)
+		if len(matched) != 1 || matched[0].Name != "sample/dropped/payload.exe" || matched[0].SuspiciousObject != &suspiciousObjects[1] {
+			t.Errorf("%q: wrong cross reference: %v", password, matched)
+		}
+		for _, report := range p.Reports {
+			var result struct {
+				RiskLevel string `json:"riskLevel" xml:"riskLevel"`
+			}
+			if err := report.Decode(&result); err != nil || result.RiskLevel != "high" {
+				t.Errorf("%q: %s: %v, %+v", password, report.Name, err, result)
+			}
+		}
+		data, err := p.PCAPs[0].Bytes()
+		if err != nil || !bytes.Equal(data, files["sample/traffic.pcap"]) {
+			t.Errorf("%q: wrong pcap: %v, %q", password, err, data)
+		}
+		if err := p.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+	for _, password := range []string{"wrong", ""} {
+		p, err := OpenInvestigationPackage(filepath.Join(dir, "packagevirus.zip"), password)
+		if err != nil {
+			t.Fatalf("%q: unreadable dropped files should not fail package: %v", password, err)
+		}
+		for _, entry := range p.Dropped {
+			if !errors.Is(entry.Err, ErrArchivePassword) || entry.Digest.SHA1 != "" {
+				t.Errorf("%q: %s: expected ErrArchivePassword, got %v", password, entry.Name, entry.Err)
+			}
+		}
+		if len(p.PCAPs) != 1 || len(p.Reports) != 2 {
+			t.Errorf("%q: other files are lost: %+v", password, p)
+		}
+		p.Close()
+	}
+	p, err := OpenInvestigationPackage(filepath.Join(dir, "package.zip"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Dropped[0].hash(3); !errors.Is(err, ErrArchiveLimit) {
+		t.Errorf("expected ErrArchiveLimit, got %v", err)
+	}
+	p.Close()
+	data, err := os.ReadFile(filepath.Join(dir, "package.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = ReadInvestigationPackage(bytes.NewReader(data), int64(len(data)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Entries()) != len(files) {
+		t.Errorf("expected %d entries, got %d", len(files), len(p.Entries()))
+	}
+}
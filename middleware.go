@@ -0,0 +1,197 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	middleware.go - request/response middleware chain and built-in middlewares
+*/
+
+package vone
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoResponse - middleware chain returned no error and no response
+var ErrNoResponse = errors.New("middleware returned no response")
+
+// Call - Vision One API call as seen by middleware
+type Call struct {
+	Method    string         // HTTP method
+	URL       string         // Full request URL
+	Operation string         // Name of VOne method created request, like "SandboxSubmitFile"
+	Attempt   int            // One based attempt number. Greater than one for retries
+	Request   *http.Request  // Request to be sent. Middleware can change it before calling next
+	Response  *http.Response // Response. Available after next returned. nil if no response was received
+	Duration  time.Duration  // Duration of HTTP exchange. Available after next returned
+}
+
+// CallFunc - function performing Vision One API call
+type CallFunc func(call *Call) error
+
+// Middleware - wrap CallFunc to inspect or change request and response
+type Middleware func(next CallFunc) CallFunc
+
+// Use - add middlewares. Middlewares are called in the order they were added,
+// so the first one added sees request first and response last. Each
+// retry attempt passes middleware chain again
+func (v *VOne) Use(middleware ...Middleware) *VOne {
+	v.middlewares = append(v.middlewares, middleware...)
+	return v
+}
+
+// handler - return send wrapped with all middlewares
+func (v *VOne) handler() CallFunc {
+	h := v.send
+	for i := len(v.middlewares) - 1; i >= 0; i-- {
+		h = v.middlewares[i](h)
+	}
+	return h
+}
+
+// redactedHeaders - return copy of headers with Authorization value hidden
+func redactedHeaders(header http.Header) http.Header {
+	result := header.Clone()
+	if result.Get("Authorization") != "" {
+		result.Set("Authorization", redactedToken)
+	}
+	return result
+}
+
+// LoggingMiddleware - log each call using structured logger. Successful calls
+// are logged with Debug level and failed ones with Warn level. Token is redacted
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next CallFunc) CallFunc {
+		return func(call *Call) error {
+			err := next(call)
+			attrs := []slog.Attr{
+				slog.String("operation", call.Operation),
+				slog.String("method", call.Method),
+				slog.String("url", call.URL),
+				slog.Duration("duration", call.Duration),
+				slog.Any("requestHeaders", redactedHeaders(call.Request.Header)),
+			}
+			if call.Response != nil {
+				attrs = append(attrs, slog.Int("status", call.Response.StatusCode))
+			}
+			level := slog.LevelDebug
+			if err != nil {
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			logger.LogAttrs(context.WithoutCancel(call.Request.Context()), level, "vone call", attrs...)
+			return err
+		}
+	}
+}
+
+// HeaderMiddleware - set request header to given value
+func HeaderMiddleware(name, value string) Middleware {
+	return func(next CallFunc) CallFunc {
+		return func(call *Call) error {
+			call.Request.Header.Set(name, value)
+			return next(call)
+		}
+	}
+}
+
+// UserAgentMiddleware - set User-Agent header of each request
+func UserAgentMiddleware(userAgent string) Middleware {
+	return HeaderMiddleware("User-Agent", userAgent)
+}
+
+// TraceIDMiddleware - set TMV1-Trace-ID header of each request to value
+// returned by newID. If newID is nil, random UUID is used
+func TraceIDMiddleware(newID func() string) Middleware {
+	if newID == nil {
+		newID = uuid.NewString
+	}
+	return func(next CallFunc) CallFunc {
+		return func(call *Call) error {
+			call.Request.Header.Set("TMV1-Trace-ID", newID())
+			return next(call)
+		}
+	}
+}
+
+// CallCounter - count calls and failed calls for each operation. Usage:
+//
+//	counter := vone.NewCallCounter()
+//	v1.Use(counter.Middleware)
+type CallCounter struct {
+	mu     sync.Mutex
+	calls  map[string]int
+	errors map[string]int
+}
+
+// NewCallCounter - create new counter
+func NewCallCounter() *CallCounter {
+	return &CallCounter{
+		calls:  make(map[string]int),
+		errors: make(map[string]int),
+	}
+}
+
+// Middleware - implement Middleware
+func (c *CallCounter) Middleware(next CallFunc) CallFunc {
+	return func(call *Call) error {
+		err := next(call)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.calls[call.Operation]++
+		if err != nil {
+			c.errors[call.Operation]++
+		}
+		return err
+	}
+}
+
+// Calls - return amount of calls for each operation
+func (c *CallCounter) Calls() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.calls)
+}
+
+// Errors - return amount of failed calls for each operation
+func (c *CallCounter) Errors() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.errors)
+}
+
+// Page - one page fetched by Paginator.Range as seen by page middleware
+type Page struct {
+	Operation string // Name of VOne method created request, like "WorkbenchListAlerts"
+	Number    int    // One based page number
+	Items     int    // Amount of items. Available after next returned
+}
+
+// PageFunc - function fetching one page. Requests for the page are made with ctx
+type PageFunc func(ctx context.Context, page *Page) error
+
+// PageMiddleware - wrap PageFunc, for example to put all requests of the page
+// into common context
+type PageMiddleware func(next PageFunc) PageFunc
+
+// UsePage - add page middlewares called by Paginator.Range for each page.
+// Middlewares are called in the order they were added
+func (v *VOne) UsePage(middleware ...PageMiddleware) *VOne {
+	v.pageMiddlewares = append(v.pageMiddlewares, middleware...)
+	return v
+}
+
+// pageHandler - return f wrapped with all page middlewares
+func (v *VOne) pageHandler(f PageFunc) PageFunc {
+	for i := len(v.pageMiddlewares) - 1; i >= 0; i-- {
+		f = v.pageMiddlewares[i](f)
+	}
+	return f
+}
@@ -0,0 +1,87 @@
+package vone
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var userAgent, traceID string
+	v1 := newTestVOne(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		traceID = r.Header.Get("TMV1-Trace-ID")
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v3.0/sandbox/submissionUsage" {
+			w.Write([]byte(`{"submissionReserveCount":100}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"NotFound","message":"not found"}}`))
+	}))
+	var log bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&log, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	counter := NewCallCounter()
+	var order []string
+	tracer := func(name string) Middleware {
+		return func(next CallFunc) CallFunc {
+			return func(call *Call) error {
+				order = append(order, name)
+				return next(call)
+			}
+		}
+	}
+	v1.Use(
+		tracer("first"),
+		LoggingMiddleware(logger),
+		UserAgentMiddleware("vone-test/1.0"),
+		TraceIDMiddleware(func() string { return "trace-1" }),
+		counter.Middleware,
+		tracer("last"),
+	)
+	ctx := context.Background()
+	if _, err := v1.SandboxDailyReserve().Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v1.SandboxAnalysisResults("5f1a1a0e-5b9b-4c59-a7b2-2a7b7a8a3d1c").Do(ctx); err == nil {
+		t.Fatal("expected error")
+	}
+	if userAgent != "vone-test/1.0" || traceID != "trace-1" {
+		t.Errorf("headers are not injected: %q %q", userAgent, traceID)
+	}
+	if strings.Join(order, ",") != "first,last,first,last" {
+		t.Errorf("wrong middleware order: %v", order)
+	}
+	if calls := counter.Calls(); calls["SandboxDailyReserve"] != 1 || calls["SandboxAnalysisResults"] != 1 {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+	if errs := counter.Errors(); len(errs) != 1 || errs["SandboxAnalysisResults"] != 1 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if strings.Contains(log.String(), "secret-token") {
+		t.Errorf("token is not redacted: %s", log.String())
+	}
+	for _, expected := range []string{`"operation":"SandboxDailyReserve"`, `"status":404`, `"level":"WARN"`} {
+		if !strings.Contains(log.String(), expected) {
+			t.Errorf("%s is missing in log: %s", expected, log.String())
+		}
+	}
+}
+
+func TestMiddlewareNoResponse(t *testing.T) {
+	v1 := newTestVOne(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	}))
+	v1.Use(func(next CallFunc) CallFunc {
+		return func(call *Call) error {
+			return nil
+		}
+	})
+	if _, err := v1.SandboxDailyReserve().Do(context.Background()); !errors.Is(err, ErrNoResponse) {
+		t.Errorf("expected ErrNoResponse, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	operation.go - follow Operation-Location of asynchronous Vision One tasks
+*/
+
+package vone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoResourceLocation = errors.New("operation has no resource location")
+	ErrForeignLocation    = errors.New("location is outside of Vision One domain")
+)
+
+// OperationStatus - status resource of asynchronous task
+type OperationStatus interface {
+	// Done - return whether task is finished successfully or not
+	Done() bool
+	// Err - return error if task failed
+	Err() error
+	// Location - return URL of task result resource. Empty if there is no such resource
+	Location() string
+}
+
+// operationStatus - pointer to status struct implementing OperationStatus
+type operationStatus[S any] interface {
+	*S
+	OperationStatus
+}
+
+// Operation - long running Vision One task. Status of type S is polled from
+// Operation-Location URL until task is done. Then result of type R is
+// fetched from location provided by status. Usage:
+//
+//	op := vone.NewOperation[vone.SandboxSubmissionStatusResponse, vone.SandboxAnalysisResultsResponseItem](v1, location)
+//	result, status, err := op.Result(ctx)
+type Operation[S, R any] struct {
+	// PollInterval - delay between the first and the second status requests
+	PollInterval time.Duration
+	// MaxPollInterval - upper limit of delay between status requests
+	MaxPollInterval time.Duration
+	// PollMultiplier - growth factor of delay between status requests
+	PollMultiplier float64
+	// OnPoll - if not nil, called after each status request with amount of requests made so far
+	OnPoll func(status *S, polls int)
+
+	vone        *VOne
+	location    string
+	status      func(*S) OperationStatus
+	fetchStatus func(ctx context.Context) (*S, time.Duration, error)
+	fetchResult func(ctx context.Context, location string) (*R, error)
+}
+
+// NewOperation - create operation polling status from given Operation-Location
+// URL each 5 seconds up to each minute. Location can be absolute URL on
+// Vision One domain or path
+func NewOperation[S, R any, PS operationStatus[S]](v1 *VOne, location string) *Operation[S, R] {
+	o := &Operation[S, R]{
+		PollInterval:    5 * time.Second,
+		MaxPollInterval: time.Minute,
+		PollMultiplier:  1.5,
+		vone:            v1,
+		location:        location,
+		status: func(s *S) OperationStatus {
+			return PS(s)
+		},
+	}
+	o.fetchStatus = func(ctx context.Context) (*S, time.Duration, error) {
+		return newLocationRequest[S](v1, "GetOperationStatus", o.location).Do(ctx)
+	}
+	o.fetchResult = func(ctx context.Context, location string) (*R, error) {
+		result, _, err := newLocationRequest[R](v1, "GetOperationResult", location).Do(ctx)
+		return result, err
+	}
+	return o
+}
+
+// Location - return URL of operation status
+func (o *Operation[S, R]) Location() string {
+	return o.location
+}
+
+// Poll - request status once. Returns delay requested by Retry-After header (zero if missing)
+func (o *Operation[S, R]) Poll(ctx context.Context) (*S, time.Duration, error) {
+	return o.fetchStatus(ctx)
+}
+
+// Wait - poll status until operation is done. Delay between requests grows
+// with each request but is never shorter than Retry-After header value. If
+// operation failed, its final status is returned along with the error
+func (o *Operation[S, R]) Wait(ctx context.Context) (*S, error) {
+	for polls := 1; ; polls++ {
+		status, retryAfter, err := o.fetchStatus(ctx)
+		if rateLimitErr, ok := IsRateLimit(err); ok {
+			retryAfter = time.Duration(rateLimitErr.Reset) * time.Second
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", o.location, err)
+		} else {
+			if o.OnPoll != nil {
+				o.OnPoll(status, polls)
+			}
+			if s := o.status(status); s.Done() {
+				return status, s.Err()
+			}
+		}
+		if err := sleep(ctx, max(o.delay(polls), retryAfter), nil); err != nil {
+			return status, err
+		}
+	}
+}
+
+// Result - wait for operation to finish and fetch resource from location provided by its status
+func (o *Operation[S, R]) Result(ctx context.Context) (*R, *S, error) {
+	status, err := o.Wait(ctx)
+	if err != nil {
+		return nil, status, err
+	}
+	location := o.status(status).Location()
+	if location == "" {
+		return nil, status, fmt.Errorf("%s: %w", o.location, ErrNoResourceLocation)
+	}
+	result, err := o.fetchResult(ctx, location)
+	if err != nil {
+		return nil, status, fmt.Errorf("%s: %w", location, err)
+	}
+	return result, status, nil
+}
+
+// delay - pause after given (one based) status request
+func (o *Operation[S, R]) delay(polls int) time.Duration {
+	return backoff{
+		initial:    o.PollInterval,
+		max:        o.MaxPollInterval,
+		multiplier: o.PollMultiplier,
+	}.delay(polls)
+}
+
+// locationResponseHeaders - headers of operation status response
+type locationResponseHeaders struct {
+	RetryAfter string `header:"Retry-After"`
+}
+
+// locationRequest - GET resource of type T by URL provided by Vision One
+type locationRequest[T any] struct {
+	baseRequest
+	name            string
+	location        string
+	response        T
+	responseHeaders locationResponseHeaders
+}
+
+var _ vOneRequest = &locationRequest[SandboxSubmissionStatusResponse]{}
+
+func newLocationRequest[T any](v1 *VOne, name string, location string) *locationRequest[T] {
+	f := &locationRequest[T]{
+		name:     name,
+		location: location,
+	}
+	f.baseRequest.init(v1)
+	return f
+}
+
+// Do - return resource and delay requested by Retry-After header
+func (f *locationRequest[T]) Do(ctx context.Context) (*T, time.Duration, error) {
+	location, err := f.vone.resolveLocation(f.location)
+	if err != nil {
+		return nil, 0, err
+	}
+	f.location = location
+	if err := f.checkUsed(); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", f.name, err)
+	}
+	if err := f.vone.call(ctx, f); err != nil {
+		return nil, 0, err
+	}
+	return &f.response, parseRetryAfter(f.responseHeaders.RetryAfter, time.Now()), nil
+}
+
+func (f *locationRequest[T]) operation() string {
+	return f.name
+}
+
+func (f *locationRequest[T]) uri() string {
+	return f.location
+}
+
+func (f *locationRequest[T]) responseStruct() any {
+	return &f.response
+}
+
+func (f *locationRequest[T]) responseHeader() any {
+	return &f.responseHeaders
+}
+
+// resolveLocation - return absolute URL for location returned by Vision One.
+// Locations on other hosts are rejected not to send token to them
+func (v *VOne) resolveLocation(location string) (string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", location, err)
+	}
+	if !u.IsAbs() {
+		return "https://" + v.Domain + "/" + strings.TrimPrefix(u.String(), "/"), nil
+	}
+	if u.Scheme != "https" || !strings.EqualFold(u.Host, v.Domain) {
+		return "", fmt.Errorf("%w: %s", ErrForeignLocation, location)
+	}
+	return u.String(), nil
+}
+
+// parseRetryAfter - return delay from Retry-After header value given either
+// in seconds or as HTTP date. Zero if value is empty or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
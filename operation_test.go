@@ -0,0 +1,102 @@
+package vone_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{"-3", 0},
+		{"Sat, 10 Jan 2026 12:00:30 GMT", 30 * time.Second},
+		{"Sat, 10 Jan 2026 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tc := range testCases {
+		if actual := vone.ParseRetryAfter(tc.value, now); actual != tc.expected {
+			t.Errorf("%q: expected %v, got %v", tc.value, tc.expected, actual)
+		}
+	}
+}
+
+func TestResolveLocation(t *testing.T) {
+	v1 := vone.NewVOne("api.xdr.trendmicro.com", "token")
+	testCases := []struct {
+		location string
+		expected string
+	}{
+		{"https://api.xdr.trendmicro.com/v3.0/sandbox/tasks/1", "https://api.xdr.trendmicro.com/v3.0/sandbox/tasks/1"},
+		{"https://API.xdr.trendmicro.com/v3.0/sandbox/tasks/1", "https://API.xdr.trendmicro.com/v3.0/sandbox/tasks/1"},
+		{"/v3.0/sandbox/tasks/1", "https://api.xdr.trendmicro.com/v3.0/sandbox/tasks/1"},
+	}
+	for _, tc := range testCases {
+		actual, err := vone.ResolveLocation(v1, tc.location)
+		if err != nil || actual != tc.expected {
+			t.Errorf("%s: expected %s, got %s (%v)", tc.location, tc.expected, actual, err)
+		}
+	}
+	for _, location := range []string{"https://example.com/v3.0/sandbox/tasks/1", "http://api.xdr.trendmicro.com/v3.0/sandbox/tasks/1"} {
+		if _, err := vone.ResolveLocation(v1, location); !errors.Is(err, vone.ErrForeignLocation) {
+			t.Errorf("%s: expected vone.ErrForeignLocation, got %v", location, err)
+		}
+	}
+}
+
+func TestSandboxOperation(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.AnalysisPolls = 1
+	s.RetryAfter = 1
+	v1 := s.NewVOne()
+	ctx := context.Background()
+	content := []byte("sample content")
+	submit := v1.SandboxSubmitFile()
+	if err := submit.SetReader(ctx, bytes.NewReader(content), "sample.exe"); err != nil {
+		t.Fatal(err)
+	}
+	response, headers, err := submit.Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operation := v1.SandboxOperation(response.ID, headers.OperationLocation)
+	operation.PollInterval = time.Millisecond
+	var polls []vone.Status
+	operation.OnPoll = func(status *vone.SandboxSubmissionStatusResponse, _ int) {
+		polls = append(polls, status.Status)
+	}
+	start := time.Now()
+	result, status, err := operation.Result(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Retry-After is ignored: %v", elapsed)
+	}
+	if fmt.Sprint(polls) != "[running succeeded]" || status.Status != vone.StatusSucceeded {
+		t.Errorf("unexpected polls: %v", polls)
+	}
+	if result.ID != response.ID || result.Digest.SHA1 != response.Digest.SHA1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if count := s.RequestCount("/v3.0/sandbox/analysisResults/" + response.ID); count != 1 {
+		t.Errorf("expected 1 analysis results request, got %d", count)
+	}
+
+	foreign := v1.SandboxOperation(response.ID, "https://example.com/v3.0/sandbox/tasks/"+response.ID)
+	if _, err := foreign.Wait(ctx); !errors.Is(err, vone.ErrForeignLocation) {
+		t.Errorf("expected ErrForeignLocation, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	OpenTelemetry instrumentation
+
+	otelvone.go - spans and metrics for Vision One API calls
+*/
+
+// Package otelvone provides optional OpenTelemetry instrumentation of vone SDK.
+//
+// Each Vision One API request gets its own client span. Paginator.Range gets
+// parent span for each page. Request latency and sandbox submission quota
+// are recorded as histograms:
+//
+//	v1 := vone.NewVOne(domain, token)
+//	if err := otelvone.Instrument(v1); err != nil {
+//	...
+package otelvone
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/mpkondrashin/vone"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName - instrumentation scope name of tracer and meter
+const ScopeName = "github.com/mpkondrashin/vone/otelvone"
+
+// Attribute keys set by instrumentation
+const (
+	AttrOperation          = attribute.Key("vone.operation")
+	AttrErrorCode          = attribute.Key("vone.error_code")
+	AttrRetryCount         = attribute.Key("vone.retry_count")
+	AttrRateLimitLimit     = attribute.Key("vone.ratelimit.limit")
+	AttrRateLimitWindow    = attribute.Key("vone.ratelimit.window")
+	AttrRateLimitRemaining = attribute.Key("vone.ratelimit.remaining")
+	AttrRateLimitReset     = attribute.Key("vone.ratelimit.reset")
+	AttrPage               = attribute.Key("vone.page")
+	AttrPageItems          = attribute.Key("vone.page.items")
+	AttrQuota              = attribute.Key("vone.quota")
+	AttrMethod             = attribute.Key("http.request.method")
+	AttrStatus             = attribute.Key("http.response.status_code")
+	AttrPath               = attribute.Key("url.path")
+)
+
+// rateLimitHeaders - response headers put to span attributes
+var rateLimitHeaders = map[string]attribute.Key{
+	"RateLimit-Limit":     AttrRateLimitLimit,
+	"RateLimit-Window":    AttrRateLimitWindow,
+	"RateLimit-Remaining": AttrRateLimitRemaining,
+	"RateLimit-Reset":     AttrRateLimitReset,
+}
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// Option - instrumentation option
+type Option func(*config)
+
+// WithTracerProvider - use given tracer provider instead of global one
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tracerProvider
+	}
+}
+
+// WithMeterProvider - use given meter provider instead of global one
+func WithMeterProvider(meterProvider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = meterProvider
+	}
+}
+
+type instrumentation struct {
+	v1       *vone.VOne
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	quota    metric.Int64Histogram
+}
+
+// Instrument - add tracing and metrics middlewares to v1
+func Instrument(v1 *vone.VOne, options ...Option) error {
+	c := &config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	meter := c.meterProvider.Meter(ScopeName)
+	duration, err := meter.Float64Histogram("vone.client.request.duration",
+		metric.WithDescription("Duration of Vision One API requests"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	quota, err := meter.Int64Histogram("vone.sandbox.submission.quota",
+		metric.WithDescription("Sandbox submission quota values returned by Vision One"),
+		metric.WithUnit("{submission}"))
+	if err != nil {
+		return err
+	}
+	i := &instrumentation{
+		v1:       v1,
+		tracer:   c.tracerProvider.Tracer(ScopeName),
+		duration: duration,
+		quota:    quota,
+	}
+	v1.Use(i.middleware)
+	v1.UsePage(i.pageMiddleware)
+	return nil
+}
+
+func (i *instrumentation) middleware(next vone.CallFunc) vone.CallFunc {
+	return func(call *vone.Call) error {
+		ctx, span := i.tracer.Start(call.Request.Context(), call.Operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				AttrOperation.String(call.Operation),
+				AttrMethod.String(call.Method),
+				AttrPath.String(call.Request.URL.Path),
+				AttrRetryCount.Int(call.Attempt-1),
+			))
+		defer span.End()
+		call.Request = call.Request.WithContext(ctx)
+		err := next(call)
+
+		metricAttrs := []attribute.KeyValue{
+			AttrOperation.String(call.Operation),
+			AttrMethod.String(call.Method),
+		}
+		if call.Response != nil {
+			status := AttrStatus.Int(call.Response.StatusCode)
+			span.SetAttributes(status)
+			metricAttrs = append(metricAttrs, status)
+			for header, key := range rateLimitHeaders {
+				if value, err := strconv.Atoi(call.Response.Header.Get(header)); err == nil {
+					span.SetAttributes(key.Int(value))
+				}
+			}
+			i.recordQuota(call)
+		}
+		i.duration.Record(ctx, call.Duration.Seconds(), metric.WithAttributes(metricAttrs...))
+		if err != nil {
+			var vOneErr vone.Error
+			if errors.As(err, &vOneErr) {
+				span.SetAttributes(AttrErrorCode.String(vOneErr.Code.String()))
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	}
+}
+
+// recordQuota - record submission quota headers if response has them
+func (i *instrumentation) recordQuota(call *vone.Call) {
+	if call.Response.Header.Get("TMV1-Submission-Reserve-Count") == "" {
+		return
+	}
+	var headers vone.SandboxSubmitFileResponseHeaders
+	if err := i.v1.PopulateResponseStruct(&headers, call.Response.Header); err != nil {
+		return
+	}
+	ctx := call.Request.Context()
+	for quota, value := range map[string]int{
+		"reserve":   headers.SubmissionReserveCount,
+		"remaining": headers.SubmissionRemainingCount,
+		"count":     headers.SubmissionCount,
+		"exemption": headers.SubmissionExemptionCount,
+	} {
+		i.quota.Record(ctx, int64(value), metric.WithAttributes(
+			AttrOperation.String(call.Operation),
+			AttrQuota.String(quota),
+		))
+	}
+}
+
+func (i *instrumentation) pageMiddleware(next vone.PageFunc) vone.PageFunc {
+	return func(ctx context.Context, page *vone.Page) error {
+		ctx, span := i.tracer.Start(ctx, page.Operation+" page",
+			trace.WithAttributes(
+				AttrOperation.String(page.Operation),
+				AttrPage.Int(page.Number),
+			))
+		defer span.End()
+		err := next(ctx, page)
+		span.SetAttributes(AttrPageItems.Int(page.Items))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	}
+}
@@ -0,0 +1,135 @@
+package otelvone
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func attributes(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	result := make(map[attribute.Key]attribute.Value)
+	for _, kv := range kvs {
+		result[kv.Key] = kv.Value
+	}
+	return result
+}
+
+func TestInstrument(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.PageSize = 2
+	s.RateLimit = 100
+	for i := 0; i < 3; i++ {
+		s.AddAlerts(vone.WorkbenchAlert{ID: fmt.Sprintf("WB-%d", i)})
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	v1 := s.NewVOne()
+	if err := Instrument(v1, WithTracerProvider(tracerProvider), WithMeterProvider(meterProvider)); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, err := range v1.WorkbenchListAlerts().Paginator().Range(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	submit := v1.SandboxSubmitFile()
+	if err := submit.SetReader(ctx, strings.NewReader("content"), "sample.exe"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := submit.Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	retryPolicy := vone.DefaultRetryPolicy()
+	retryPolicy.InitialBackoff = time.Millisecond
+	v1.SetRetryPolicy(retryPolicy)
+	s.InjectFault(vonetest.Fault{Path: "/v3.0/sandbox/submissionUsage", Status: 503})
+	if _, err := v1.SandboxDailyReserve().Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	pages := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		if span.Name == "WorkbenchListAlerts page" {
+			pages[span.SpanContext.SpanID().String()] = span
+		}
+	}
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 page spans, got %d", len(pages))
+	}
+	var listSpans, reserveSpans []tracetest.SpanStub
+	for _, span := range spans {
+		switch span.Name {
+		case "WorkbenchListAlerts":
+			listSpans = append(listSpans, span)
+			if _, ok := pages[span.Parent.SpanID().String()]; !ok {
+				t.Errorf("request span is not child of page span")
+			}
+			attrs := attributes(span.Attributes)
+			if attrs[AttrPath].AsString() != "/v3.0/workbench/alerts" || attrs[AttrStatus].AsInt64() != 200 {
+				t.Errorf("unexpected attributes: %v", span.Attributes)
+			}
+			if attrs[AttrRateLimitLimit].AsInt64() != 100 {
+				t.Errorf("rate limit attribute is missing: %v", span.Attributes)
+			}
+		case "SandboxDailyReserve":
+			reserveSpans = append(reserveSpans, span)
+		}
+	}
+	if len(listSpans) != 2 {
+		t.Errorf("expected 2 request spans, got %d", len(listSpans))
+	}
+	if len(reserveSpans) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(reserveSpans))
+	}
+	failed := attributes(reserveSpans[0].Attributes)
+	if failed[AttrErrorCode].AsString() != vone.ErrorCodeInternalServerError.String() || failed[AttrStatus].AsInt64() != 503 {
+		t.Errorf("unexpected failed span attributes: %v", reserveSpans[0].Attributes)
+	}
+	if retry := attributes(reserveSpans[1].Attributes)[AttrRetryCount].AsInt64(); retry != 1 {
+		t.Errorf("expected retry count 1, got %d", retry)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]bool)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			found[m.Name] = true
+			if m.Name != "vone.sandbox.submission.quota" {
+				continue
+			}
+			histogram := m.Data.(metricdata.Histogram[int64])
+			for _, point := range histogram.DataPoints {
+				quota, _ := point.Attributes.Value(AttrQuota)
+				if quota.AsString() != "reserve" {
+					continue
+				}
+				if max, _ := point.Max.Value(); max != vonetest.DefaultDailyReserve {
+					t.Errorf("unexpected reserve: %d", max)
+				}
+			}
+		}
+	}
+	for _, name := range []string{"vone.client.request.duration", "vone.sandbox.submission.quota"} {
+		if !found[name] {
+			t.Errorf("metric %s is missing", name)
+		}
+	}
+}
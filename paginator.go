@@ -3,13 +3,19 @@ package vone
 import (
 	"context"
 	"errors"
+	"fmt"
 	"iter"
+	"sync"
 	"time"
 )
 
 type paginatedRequest[T any] interface {
 	Do(ctx context.Context) (*T, error)
+	operation() string
+	client() *VOne
+	query() map[string]string
 	nextLink() string
+	setNextLink(link string)
 	resetPagination()
 	isDone(*T) bool
 }
@@ -17,6 +23,12 @@ type paginatedRequest[T any] interface {
 type Paginator[T any, Item any] struct {
 	req   paginatedRequest[T]
 	items func(*T) []Item
+
+	mu       sync.Mutex
+	position Checkpoint
+	store    CheckpointStore
+	key      string
+	prefetch int
 }
 
 func NewPaginator[T any, Item any](
@@ -30,45 +42,226 @@ func NewPaginator[T any, Item any](
 	}
 }
 
+// Checkpoint - return current position of Range. Range called on paginator
+// resumed from this checkpoint yields the items following the last one yielded
+func (p *Paginator[T, Item]) Checkpoint() *Checkpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	checkpoint := p.position
+	checkpoint.Operation = p.req.operation()
+	checkpoint.Query = p.req.query()
+	checkpoint.Updated = time.Now()
+	return &checkpoint
+}
+
+// CheckpointTo - save checkpoint to store under given key after each page
+// and when Range returns
+func (p *Paginator[T, Item]) CheckpointTo(store CheckpointStore, key string) *Paginator[T, Item] {
+	p.store = store
+	p.key = key
+	return p
+}
+
+// ResumeFrom - continue from checkpoint stored under given key and keep saving
+// checkpoints there. If there is no checkpoint, Range starts from the beginning.
+// Returns ErrCheckpointMismatch if checkpoint was made for other operation,
+// filter or parameters
+func (p *Paginator[T, Item]) ResumeFrom(ctx context.Context, store CheckpointStore, key string) (*Paginator[T, Item], error) {
+	checkpoint, err := store.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != nil {
+		if err := checkpoint.matches(p.req.operation(), p.req.query()); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		p.mu.Lock()
+		p.position = *checkpoint
+		p.mu.Unlock()
+	}
+	return p.CheckpointTo(store, key), nil
+}
+
+// SaveCheckpoint - save current position to store set by CheckpointTo or ResumeFrom
+func (p *Paginator[T, Item]) SaveCheckpoint(ctx context.Context) error {
+	if p.store == nil {
+		return nil
+	}
+	return p.store.Save(ctx, p.key, p.Checkpoint())
+}
+
+// moveTo - update position of Range
+func (p *Paginator[T, Item]) moveTo(update func(position *Checkpoint)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	update(&p.position)
+}
+
+// Prefetch - fetch next pages in background while current page is consumed.
+// Up to pages fetched pages are kept in buffer. Zero (default) disables prefetching.
+// Requests are still made one by one through configured RateLimiter and the
+// first error stops iteration
+func (p *Paginator[T, Item]) Prefetch(pages int) *Paginator[T, Item] {
+	p.prefetch = max(pages, 0)
+	return p
+}
+
+// fetchedPage - page passed from fetching to consuming part of Range
+type fetchedPage[Item any] struct {
+	next  string // link of the next page
+	done  bool   // page is the last one
+	items []Item
+	err   error // error stopping iteration. Reported after items
+}
+
+// Range - iterate over items of all pages. Paginator with CheckpointTo or
+// ResumeFrom continues from the saved position, otherwise each Range starts
+// from the first page
 func (p *Paginator[T, Item]) Range(ctx context.Context) iter.Seq2[*Item, error] {
 	return func(yield func(*Item, error) bool) {
-		for {
-			resp, err := p.req.Do(ctx)
-			if err != nil {
-				if rl, ok := IsRateLimit(err); ok {
-					timer := time.NewTimer(time.Duration(rl.Reset) * time.Second)
-					select {
-					case <-ctx.Done():
-						timer.Stop()
-						yield(nil, ctx.Err())
-						return
-					case <-timer.C:
-					}
-					continue
+		p.mu.Lock()
+		if p.store == nil {
+			// without checkpoints each Range starts from the beginning
+			p.position = Checkpoint{}
+		}
+		start := p.position
+		p.mu.Unlock()
+		if start.Done {
+			return
+		}
+		// consumer stopping iteration or error can not be reported, so save is best effort
+		defer p.SaveCheckpoint(context.WithoutCancel(ctx))
+		skip := start.Offset
+		consume := func(page *fetchedPage[Item]) bool {
+			for i := skip; i < len(page.items); i++ {
+				item := page.items[i]
+				more := yield(&item, nil)
+				p.moveTo(func(position *Checkpoint) {
+					position.Offset = i + 1
+					position.Yielded++
+				})
+				if !more {
+					return false
 				}
-
+			}
+			skip = 0
+			if page.err != nil {
+				yield(nil, page.err)
+				return false
+			}
+			if page.done {
+				p.moveTo(func(position *Checkpoint) {
+					position.Done = true
+				})
+				return false
+			}
+			p.moveTo(func(position *Checkpoint) {
+				position.PageLink = page.next
+				position.Offset = 0
+			})
+			if err := p.SaveCheckpoint(ctx); err != nil {
 				yield(nil, err)
-				return
+				return false
 			}
-
-			items := p.items(resp)
-			for i := range items {
-				item := items[i]
-				if !yield(&item, nil) {
-					return
+			return true
+		}
+		if p.prefetch == 0 {
+			p.fetchPages(ctx, start.PageLink, consume)
+			return
+		}
+		fetchCtx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer cancel()
+		pages := make(chan *fetchedPage[Item], p.prefetch)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(pages)
+			p.fetchPages(fetchCtx, start.PageLink, func(page *fetchedPage[Item]) bool {
+				select {
+				case pages <- page:
+					return true
+				case <-fetchCtx.Done():
+					return false
 				}
+			})
+		}()
+		for page := range pages {
+			if err := ctx.Err(); err != nil {
+				page = &fetchedPage[Item]{err: err}
 			}
-
-			if p.req.isDone(resp) {
+			if !consume(page) {
 				return
 			}
+		}
+		// fetching was cancelled before error was passed
+		yield(nil, ctx.Err())
+	}
+}
 
-			// защита от кривого ответа API:
-			// если ещё не done, но nextLink пустой, это тупик
-			if p.req.nextLink() == "" {
-				yield(nil, errors.New("pagination stalled: not done but nextLink is empty"))
-				return
+// fetchPages - fetch pages starting from given link and pass them to emit
+// until the last page, error or emit returning false
+func (p *Paginator[T, Item]) fetchPages(ctx context.Context, link string, emit func(*fetchedPage[Item]) bool) {
+	p.req.setNextLink(link)
+	var resp *T
+	for number := 1; ; number++ {
+		pageLink := p.req.nextLink()
+		if resp != nil {
+			// decode into empty response, so items of previous page are not overwritten
+			var empty T
+			*resp = empty
+			p.req.setNextLink(pageLink)
+		}
+		var err error
+		resp, err = p.page(ctx, number)
+		if err != nil {
+			if rl, ok := IsRateLimit(err); ok {
+				if err := sleep(ctx, time.Duration(rl.Reset)*time.Second, nil); err != nil {
+					emit(&fetchedPage[Item]{err: err})
+					return
+				}
+				p.req.setNextLink(pageLink)
+				number--
+				continue
 			}
+			emit(&fetchedPage[Item]{err: err})
+			return
+		}
+		page := &fetchedPage[Item]{
+			next:  p.req.nextLink(),
+			done:  p.req.isDone(resp),
+			items: p.items(resp),
 		}
+		// защита от кривого ответа API:
+		// если ещё не done, но nextLink пустой, это тупик
+		if !page.done && page.next == "" {
+			page.err = errors.New("pagination stalled: not done but nextLink is empty")
+		}
+		if !emit(page) || page.done || page.err != nil {
+			return
+		}
+	}
+}
+
+// page - fetch one page passing it through page middlewares
+func (p *Paginator[T, Item]) page(ctx context.Context, number int) (*T, error) {
+	var resp *T
+	fetch := func(ctx context.Context, page *Page) error {
+		var err error
+		resp, err = p.req.Do(ctx)
+		if err != nil {
+			return err
+		}
+		page.Items = len(p.items(resp))
+		return nil
+	}
+	page := &Page{
+		Operation: p.req.operation(),
+		Number:    number,
+	}
+	if err := p.req.client().pageHandler(fetch)(ctx, page); err != nil {
+		return nil, err
 	}
+	return resp, nil
 }
@@ -0,0 +1,245 @@
+package vone_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+	_ "modernc.org/sqlite"
+)
+
+func TestPaginatorResume(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.PageSize = 3
+	for i := 0; i < 10; i++ {
+		s.AddAlerts(vone.WorkbenchAlert{ID: fmt.Sprintf("WB-%d", i), Severity: "high"})
+	}
+	fileStore, err := vone.NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "checkpoints.sqlite3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlStore, err := vone.NewSQLCheckpointStore(db, "checkpoints.sqlite3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, store := range map[string]vone.CheckpointStore{"file": fileStore, "sql": sqlStore} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "alerts/" + name
+			var ids []string
+			// each "process" stops after 4 items in the middle of a page
+			for run := 0; run < 4; run++ {
+				paginator, err := s.NewVOne().WorkbenchListAlerts().Filter("severity eq 'high'").
+					Paginator().ResumeFrom(ctx, store, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				count := 0
+				for alert, err := range paginator.Range(ctx) {
+					if err != nil {
+						t.Fatal(err)
+					}
+					ids = append(ids, alert.ID)
+					count++
+					if count == 4 {
+						break
+					}
+				}
+			}
+			if len(ids) != 10 {
+				t.Fatalf("expected 10 alerts, got %v", ids)
+			}
+			for i, id := range ids {
+				if expected := fmt.Sprintf("WB-%d", i); id != expected {
+					t.Errorf("expected %s, got %s", expected, id)
+				}
+			}
+			checkpoint, err := store.Load(ctx, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !checkpoint.Done || checkpoint.Yielded != 10 {
+				t.Errorf("unexpected checkpoint: %+v", checkpoint)
+			}
+			_, err = s.NewVOne().WorkbenchListAlerts().Filter("severity eq 'low'").
+				Paginator().ResumeFrom(ctx, store, key)
+			if !errors.Is(err, vone.ErrCheckpointMismatch) {
+				t.Errorf("expected ErrCheckpointMismatch, got %v", err)
+			}
+			if err := store.Delete(ctx, key); err != nil {
+				t.Fatal(err)
+			}
+			if checkpoint, err := store.Load(ctx, key); err != nil || checkpoint != nil {
+				t.Errorf("expected no checkpoint, got %v, %v", checkpoint, err)
+			}
+		})
+	}
+}
+
+func TestSearchEndPointDataResume(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.PageSize = 2
+	for i := 0; i < 5; i++ {
+		item := vone.SearchEndPointDataResponseItem{AgentGUID: fmt.Sprintf("guid-%d", i), OsName: "Windows"}
+		s.AddEndpointData(item)
+	}
+	s.AddEndpointData(vone.SearchEndPointDataResponseItem{AgentGUID: "linux", OsName: "Linux"})
+	store, err := vone.NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	var guids []string
+	// each "process" stops after 3 items, so next one resumes from second page
+	for run := 0; run < 2; run++ {
+		paginator, err := s.NewVOne().SearchEndPointData().QueryString("osName eq 'Windows'").
+			Paginator().ResumeFrom(ctx, store, "endpoints")
+		if err != nil {
+			t.Fatal(err)
+		}
+		count := 0
+		for item, err := range paginator.Range(ctx) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			guids = append(guids, item.AgentGUID)
+			count++
+			if count == 3 {
+				break
+			}
+		}
+	}
+	expected := []string{"guid-0", "guid-1", "guid-2", "guid-3", "guid-4"}
+	if !slices.Equal(guids, expected) {
+		t.Errorf("expected %v, got %v", expected, guids)
+	}
+}
+
+func TestPaginatorRangeTwice(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.PageSize = 2
+	v1 := s.NewVOne()
+	for i := 0; i < 5; i++ {
+		s.AddAlerts(vone.WorkbenchAlert{ID: fmt.Sprintf("WB-%d", i)})
+	}
+	paginator := v1.WorkbenchListAlerts().Paginator()
+	for i := 0; i < 2; i++ {
+		count := 0
+		for _, err := range paginator.Range(context.Background()) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			count++
+		}
+		if count != 5 {
+			t.Errorf("range %d: expected 5 alerts, got %d", i, count)
+		}
+	}
+}
+
+func TestPaginatorPrefetch(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.PageSize = 2
+	v1 := s.NewVOne()
+	for i := 0; i < 10; i++ {
+		s.AddAlerts(vone.WorkbenchAlert{ID: fmt.Sprintf("WB-%d", i)})
+	}
+	var ids []string
+	for alert, err := range v1.WorkbenchListAlerts().Paginator().Prefetch(2).Range(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) == 0 {
+			// while first page is consumed, two pages wait in buffer
+			// and one more waits for space in it
+			deadline := time.Now().Add(5 * time.Second)
+			for s.RequestCount("/v3.0/workbench/alerts") < 4 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			time.Sleep(50 * time.Millisecond)
+			if actual := s.RequestCount("/v3.0/workbench/alerts"); actual != 4 {
+				t.Errorf("expected 4 requests, got %d", actual)
+			}
+		}
+		ids = append(ids, alert.ID)
+	}
+	if len(ids) != 10 {
+		t.Fatalf("expected 10 alerts, got %v", ids)
+	}
+	for i, id := range ids {
+		if expected := fmt.Sprintf("WB-%d", i); id != expected {
+			t.Errorf("expected %s, got %s", expected, id)
+		}
+	}
+}
+
+func TestPaginatorPrefetchError(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.PageSize = 3
+	v1 := s.NewVOne()
+	for i := 0; i < 10; i++ {
+		s.AddAlerts(vone.WorkbenchAlert{ID: fmt.Sprintf("WB-%d", i)})
+	}
+	s.InjectFault(vonetest.Fault{Path: "/v3.0/workbench/alerts", Status: 500})
+	errorsCount := 0
+	for alert, err := range v1.WorkbenchListAlerts().Paginator().Prefetch(2).Range(context.Background()) {
+		if err == nil {
+			t.Errorf("unexpected alert: %v", alert.ID)
+			continue
+		}
+		errorsCount++
+	}
+	if errorsCount != 1 {
+		t.Errorf("expected 1 error, got %d", errorsCount)
+	}
+	if actual := s.RequestCount("/v3.0/workbench/alerts"); actual != 1 {
+		t.Errorf("expected 1 request, got %d", actual)
+	}
+}
+
+func TestPaginatorPrefetchCancel(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.PageSize = 1
+	v1 := s.NewVOne()
+	for i := 0; i < 10; i++ {
+		s.AddAlerts(vone.WorkbenchAlert{ID: fmt.Sprintf("WB-%d", i)})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count := 0
+	var lastErr error
+	for _, err := range v1.WorkbenchListAlerts().Paginator().Prefetch(1).Range(ctx) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		count++
+		if count == 2 {
+			cancel()
+		}
+	}
+	if count != 2 {
+		t.Errorf("expected iteration to stop after cancel, got %d alerts", count)
+	}
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", lastErr)
+	}
+}
@@ -0,0 +1,279 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	record_replay.go - record Vision One traffic to JSONL and replay it back
+*/
+
+package vone
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	redactedToken = "Bearer REDACTED"
+	bodyBase64    = "base64"
+)
+
+// DefaultMaxRecordedRequestBody - default Recorder.MaxRequestBody
+const DefaultMaxRecordedRequestBody = 1024 * 1024
+
+var ErrNoRecording = errors.New("no recorded response")
+
+// RecordedExchange - one request/response pair as stored in JSONL recording
+type RecordedExchange struct {
+	Method               string      `json:"method"`
+	URL                  string      `json:"url"`
+	RequestHeaders       http.Header `json:"requestHeaders,omitempty"`
+	RequestBody          string      `json:"requestBody,omitempty"`
+	RequestBodyEncoding  string      `json:"requestBodyEncoding,omitempty"`
+	RequestBodyTruncated bool        `json:"requestBodyTruncated,omitempty"`
+	Status               int         `json:"status"`
+	ResponseHeaders      http.Header `json:"responseHeaders,omitempty"`
+	ResponseBody         string      `json:"responseBody,omitempty"`
+	ResponseBodyEncoding string      `json:"responseBodyEncoding,omitempty"`
+}
+
+func encodeBody(data []byte) (string, string) {
+	if utf8.Valid(data) {
+		return string(data), ""
+	}
+	return base64.StdEncoding.EncodeToString(data), bodyBase64
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == bodyBase64 {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
+
+// recordKey - method, path and normalized query of request
+func recordKey(method string, u *url.URL) string {
+	return method + " " + u.Path + "?" + u.Query().Encode()
+}
+
+// Recorder - http.RoundTripper that writes every exchange to JSONL stream.
+// Bearer token is redacted. Request body is recorded while transport sends it,
+// so streamed uploads are not loaded into memory. Only first MaxRequestBody
+// bytes are kept and RequestBodyTruncated is set for longer bodies. Usage:
+//
+//	recorder := vone.NewRecorder(file)
+//	v1.AddTransportWrapper(recorder.Wrap)
+type Recorder struct {
+	// MaxRequestBody - amount of request body bytes to record
+	MaxRequestBody int64
+	next           http.RoundTripper
+	mu             sync.Mutex
+	encoder        *json.Encoder
+}
+
+var _ http.RoundTripper = &Recorder{}
+
+// NewRecorder - create recorder writing to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		MaxRequestBody: DefaultMaxRecordedRequestBody,
+		next:           http.DefaultTransport,
+		encoder:        json.NewEncoder(w),
+	}
+}
+
+// Wrap - set transport to be used for actual requests. Can be passed to VOne.AddTransportWrapper
+func (r *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next = next
+	return r
+}
+
+// RoundTrip - implement http.RoundTripper interface
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange := RecordedExchange{
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		RequestHeaders: make(http.Header),
+	}
+	for name, values := range req.Header {
+		if strings.HasPrefix(strings.ToUpper(name), "TMV1-") || name == "Content-Type" {
+			exchange.RequestHeaders[name] = values
+		}
+	}
+	if req.Header.Get("Authorization") != "" {
+		exchange.RequestHeaders.Set("Authorization", redactedToken)
+	}
+	var body *recordedBody
+	if req.Body != nil && req.Body != http.NoBody {
+		body = &recordedBody{ReadCloser: req.Body, limit: r.MaxRequestBody}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+	r.mu.Lock()
+	next := r.next
+	r.mu.Unlock()
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if body != nil {
+		var sent []byte
+		sent, exchange.RequestBodyTruncated = body.recorded()
+		exchange.RequestBody, exchange.RequestBodyEncoding = encodeBody(sent)
+	}
+	exchange.Status = resp.StatusCode
+	exchange.ResponseHeaders = resp.Header.Clone()
+	exchange.ResponseBody, exchange.ResponseBodyEncoding = encodeBody(data)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.encoder.Encode(exchange); err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+	return resp, nil
+}
+
+// recordedBody - request body that keeps copy of first limit bytes read by
+// transport. Transport can still be reading body from its own goroutine, so
+// access is guarded by mutex
+type recordedBody struct {
+	io.ReadCloser
+	limit     int64
+	mu        sync.Mutex
+	data      []byte
+	truncated bool
+}
+
+// Read - implement io.Reader interface
+func (b *recordedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	keep := min(int64(n), max(b.limit-int64(len(b.data)), 0))
+	b.data = append(b.data, p[:keep]...)
+	if int64(n) > keep {
+		b.truncated = true
+	}
+	return n, err
+}
+
+// recorded - copy of recorded bytes and whether body was longer than limit
+func (b *recordedBody) recorded() ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.data), b.truncated
+}
+
+// Replayer - http.RoundTripper that returns previously recorded responses
+// matching request method, path and query. Requests are never sent to network.
+// Identical requests get recorded responses in the original order; when
+// recorded responses are exhausted, the last one is repeated
+type Replayer struct {
+	mu        sync.Mutex
+	exchanges map[string][]RecordedExchange
+	served    map[string]int
+}
+
+var _ http.RoundTripper = &Replayer{}
+
+// NewReplayer - load recording from JSONL stream
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	p := &Replayer{
+		exchanges: make(map[string][]RecordedExchange),
+		served:    make(map[string]int),
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 256*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var exchange RecordedExchange
+		if err := json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return nil, fmt.Errorf("replayer: line %d: %w", line, err)
+		}
+		u, err := url.Parse(exchange.URL)
+		if err != nil {
+			return nil, fmt.Errorf("replayer: line %d: %w", line, err)
+		}
+		key := recordKey(exchange.Method, u)
+		p.exchanges[key] = append(p.exchanges[key], exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("replayer: %w", err)
+	}
+	return p, nil
+}
+
+// LoadReplayer - load recording from JSONL file
+func LoadReplayer(filePath string) (*Replayer, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return NewReplayer(file)
+}
+
+// Wrap - ignore given transport. Can be passed to VOne.AddTransportWrapper
+func (p *Replayer) Wrap(http.RoundTripper) http.RoundTripper {
+	return p
+}
+
+// RoundTrip - implement http.RoundTripper interface
+func (p *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := recordKey(req.Method, req.URL)
+	p.mu.Lock()
+	exchanges := p.exchanges[key]
+	if len(exchanges) == 0 {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNoRecording, key)
+	}
+	n := min(p.served[key], len(exchanges)-1)
+	p.served[key]++
+	exchange := exchanges[n]
+	p.mu.Unlock()
+
+	body, err := decodeBody(exchange.ResponseBody, exchange.ResponseBodyEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("replayer: %s: %w", key, err)
+	}
+	header := exchange.ResponseHeaders.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+		StatusCode:    exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,156 @@
+package vone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newTestVOne - start TLS test server and return VOne configured to use it
+func newTestVOne(t *testing.T, handler http.Handler) *VOne {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := NewVOne(u.Host, "secret-token")
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+	v1.AddTransportModifier(func(tr *http.Transport) {
+		tr.TLSClientConfig = tlsConfig.Clone()
+	})
+	return v1
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	v1 := newTestVOne(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v3.0/workbench/alerts":
+			if r.URL.Query().Get("skipToken") == "" {
+				w.Write([]byte(`{"items":[{"id":"WB-1"}],"nextLink":"https://` + r.Host + `/v3.0/workbench/alerts?skipToken=1"}`))
+				return
+			}
+			w.Write([]byte(`{"items":[{"id":"WB-2"}]}`))
+		case "/v3.0/sandbox/analysisResults/5f1a1a0e-5b9b-4c59-a7b2-2a7b7a8a3d1c/report":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte{0x25, 0x50, 0x44, 0x46, 0xff, 0xfe})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"NotFound","message":"not found"}}`))
+		}
+	}))
+	var recording bytes.Buffer
+	v1.AddTransportWrapper(NewRecorder(&recording).Wrap)
+	ctx := context.Background()
+	var ids []string
+	for alert, err := range v1.WorkbenchListAlerts().Filter("severity eq 'high'").Paginator().Range(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, alert.ID)
+	}
+	id := "5f1a1a0e-5b9b-4c59-a7b2-2a7b7a8a3d1c"
+	var pdf bytes.Buffer
+	body, err := v1.SandboxDownloadResults(id).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdf.ReadFrom(body)
+	body.Close()
+	if _, err := v1.SandboxAnalysisResults(id).Do(ctx); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 4 {
+		t.Fatalf("expected 4 calls, got %d", calls)
+	}
+	if strings.Contains(recording.String(), "secret-token") {
+		t.Errorf("token is not redacted")
+	}
+	if !strings.Contains(recording.String(), "severity eq 'high'") {
+		t.Errorf("TMV1-Filter header is not recorded")
+	}
+
+	replayer, err := NewReplayer(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offline := NewVOne("offline.example", "other-token").AddTransportWrapper(replayer.Wrap)
+	var replayedIDs []string
+	for alert, err := range offline.WorkbenchListAlerts().Filter("severity eq 'high'").Paginator().Range(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		replayedIDs = append(replayedIDs, alert.ID)
+	}
+	if strings.Join(ids, ",") != strings.Join(replayedIDs, ",") {
+		t.Errorf("expected %v, got %v", ids, replayedIDs)
+	}
+	body, err = offline.SandboxDownloadResults(id).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var replayedPDF bytes.Buffer
+	replayedPDF.ReadFrom(body)
+	body.Close()
+	if !bytes.Equal(pdf.Bytes(), replayedPDF.Bytes()) {
+		t.Errorf("expected %v, got %v", pdf.Bytes(), replayedPDF.Bytes())
+	}
+	_, err = offline.SandboxAnalysisResults(id).Do(ctx)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusNotFound {
+		t.Errorf("expected http 404, got %v", err)
+	}
+	_, err = offline.SandboxDailyReserve().Do(ctx)
+	if !errors.Is(err, ErrNoRecording) {
+		t.Errorf("expected ErrNoRecording, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("replay should not reach server, got %d calls", calls)
+	}
+}
+
+func TestRecorderRequestBodyTruncated(t *testing.T) {
+	var received int
+	v1 := newTestVOne(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received = len(data)
+		for _, name := range []string{"Reserve", "Remaining", "Exemption"} {
+			w.Header().Set("TMV1-Submission-"+name+"-Count", "0")
+		}
+		w.Header().Set("TMV1-Submission-Count", "1")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write([]byte(`[{"status":202,"body":{"id":"1"}}]`))
+	}))
+	var recording bytes.Buffer
+	recorder := NewRecorder(&recording)
+	recorder.MaxRequestBody = 16
+	v1.AddTransportWrapper(recorder.Wrap)
+	if _, _, err := v1.SandboxSubmitURLs().AddURL("https://example.com/some/long/path").Do(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var exchange RecordedExchange
+	if err := json.Unmarshal(recording.Bytes(), &exchange); err != nil {
+		t.Fatal(err)
+	}
+	if !exchange.RequestBodyTruncated {
+		t.Errorf("expected truncation marker")
+	}
+	if len(exchange.RequestBody) != 16 {
+		t.Errorf("expected 16 recorded bytes, got %q", exchange.RequestBody)
+	}
+	if received <= 16 {
+		t.Errorf("expected whole body to be sent, got %d bytes", received)
+	}
+}
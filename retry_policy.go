@@ -0,0 +1,159 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	retry_policy.go - retry transient errors with exponential backoff
+*/
+
+package vone
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// RetryPolicy - how VOne should retry requests failed with transient errors
+type RetryPolicy struct {
+	// MaxAttempts - maximum amount of attempts including the first one
+	MaxAttempts int
+	// InitialBackoff - delay before the second attempt
+	InitialBackoff time.Duration
+	// MaxBackoff - upper limit of delay between attempts
+	MaxBackoff time.Duration
+	// Multiplier - backoff growth factor for each next attempt
+	Multiplier float64
+	// Jitter - fraction (0..1) of backoff to be randomly subtracted
+	Jitter float64
+	// RetryableStatuses - HTTP statuses to be retried
+	RetryableStatuses []int
+	// RetryNonIdempotent - retry POST and PATCH requests (like SandboxSubmitFile,
+	// AddExceptions or WorkbenchModifyStatus). This can lead to duplicate submissions
+	RetryNonIdempotent bool
+}
+
+// idempotentMethods - HTTP methods that can be repeated without side effects
+var idempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPut,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// DefaultRetryableStatuses - HTTP statuses retried by DefaultRetryPolicy
+var DefaultRetryableStatuses = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy - return policy with 4 attempts and backoff from 1 to 30 seconds
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       4,
+		InitialBackoff:    1 * time.Second,
+		MaxBackoff:        30 * time.Second,
+		Multiplier:        2,
+		Jitter:            0.2,
+		RetryableStatuses: DefaultRetryableStatuses,
+	}
+}
+
+// SetRetryPolicy - set policy for retrying transient errors. nil disables retries
+func (v *VOne) SetRetryPolicy(retryPolicy *RetryPolicy) *VOne {
+	v.retryPolicy = retryPolicy
+	return v
+}
+
+// Backoff - delay before next attempt after given (one based) attempt failed
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	return backoff{
+		initial:    p.InitialBackoff,
+		max:        p.MaxBackoff,
+		multiplier: p.Multiplier,
+		jitter:     p.Jitter,
+	}.delay(attempt)
+}
+
+// ShouldRetry - check whether request with given method failed with error worth retrying
+func (p *RetryPolicy) ShouldRetry(method string, err error) bool {
+	if err == nil {
+		return false
+	}
+	if !p.RetryNonIdempotent && !slices.Contains(idempotentMethods, method) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return slices.Contains(p.RetryableStatuses, httpErr.Status)
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+	return false
+}
+
+func (v *VOne) callWithRetry(ctx context.Context, f vOneRequest) error {
+	p := v.retryPolicy
+	for attempt := 1; ; attempt++ {
+		err := v.callOnce(ctx, f)
+		if attempt >= p.MaxAttempts || !p.ShouldRetry(f.method(), err) {
+			return err
+		}
+		if sleepErr := sleep(ctx, p.Backoff(attempt), nil); sleepErr != nil {
+			return errors.Join(sleepErr, err)
+		}
+	}
+}
+
+// backoff - exponentially growing delay used for retries and polling
+type backoff struct {
+	// initial - delay after the first attempt
+	initial time.Duration
+	// max - upper limit of delay. Zero means no limit
+	max time.Duration
+	// multiplier - growth factor for each next attempt
+	multiplier float64
+	// jitter - fraction (0..1) of delay to be randomly subtracted
+	jitter float64
+}
+
+// delay - pause after given (one based) attempt
+func (b backoff) delay(attempt int) time.Duration {
+	delay := float64(b.initial)
+	for i := 1; i < attempt && (b.max <= 0 || delay < float64(b.max)); i++ {
+		delay *= b.multiplier
+	}
+	if b.max > 0 {
+		delay = min(delay, float64(b.max))
+	}
+	if b.jitter > 0 {
+		delay -= delay * b.jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// sleep - wait for given duration. Return context.Cause if ctx is done first
+// or ErrStop if stop channel is closed. stop can be nil
+func sleep(ctx context.Context, duration time.Duration, stop <-chan struct{}) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-stop:
+		return ErrStop
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,271 @@
+package vone
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.InitialBackoff = time.Millisecond
+	p.MaxBackoff = 5 * time.Millisecond
+	return p
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+	}
+	testCases := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{100, 5 * time.Second},
+	}
+	for _, tc := range testCases {
+		if actual := p.Backoff(tc.attempt); actual != tc.expected {
+			t.Errorf("attempt %d: expected %v, got %v", tc.attempt, tc.expected, actual)
+		}
+	}
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if actual := p.Backoff(1); actual < time.Second/2 || actual > time.Second {
+			t.Fatalf("backoff out of range: %v", actual)
+		}
+	}
+}
+
+func TestRetryPolicyGet(t *testing.T) {
+	calls := 0
+	v1 := newTestVOne(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"code":"ServiceUnavailable","message":"try later"}}`))
+			return
+		}
+		w.Write([]byte(`{"submissionReserveCount":100}`))
+	}))
+	v1.SetRetryPolicy(testRetryPolicy())
+	reserve, err := v1.SandboxDailyReserve().Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reserve.SubmissionReserveCount != 100 {
+		t.Errorf("unexpected reserve: %d", reserve.SubmissionReserveCount)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryPolicyMaxAttempts(t *testing.T) {
+	calls := 0
+	v1 := newTestVOne(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	v1.SetRetryPolicy(testRetryPolicy())
+	_, err := v1.SandboxDailyReserve().Do(context.Background())
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusBadGateway {
+		t.Errorf("expected http 502, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("expected 4 calls, got %d", calls)
+	}
+}
+
+func TestRetryPolicyPost(t *testing.T) {
+	calls := 0
+	var contents []string
+	v1 := newTestVOne(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		contents = append(contents, string(data))
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		for _, name := range []string{
+			"TMV1-Submission-Reserve-Count",
+			"TMV1-Submission-Remaining-Count",
+			"TMV1-Submission-Count",
+			"TMV1-Submission-Exemption-Count",
+		} {
+			w.Header().Set(name, "1")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1"}`))
+	}))
+	ctx := context.Background()
+	v1.SetRetryPolicy(testRetryPolicy())
+	submit := v1.SandboxSubmitFile()
+	if err := submit.SetReader(ctx, strings.NewReader("content"), "sample.exe"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := submit.Do(ctx); err == nil {
+		t.Fatal("POST should not be retried by default")
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+
+	policy := testRetryPolicy()
+	policy.RetryNonIdempotent = true
+	v1.SetRetryPolicy(policy)
+	calls = 0
+	contents = nil
+	submit = v1.SandboxSubmitFile()
+	if err := submit.SetReader(ctx, strings.NewReader("content"), "sample.exe"); err != nil {
+		t.Fatal(err)
+	}
+	response, _, err := submit.Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ID != "1" {
+		t.Errorf("unexpected ID: %s", response.ID)
+	}
+	if strings.Join(contents, ",") != "content,content" {
+		t.Errorf("unexpected uploaded contents: %v", contents)
+	}
+}
+
+func TestRetryPolicyPostFileReader(t *testing.T) {
+	calls := 0
+	var contents []string
+	v1 := newTestVOne(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		contents = append(contents, string(data))
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		for _, name := range []string{
+			"TMV1-Submission-Reserve-Count",
+			"TMV1-Submission-Remaining-Count",
+			"TMV1-Submission-Count",
+			"TMV1-Submission-Exemption-Count",
+		} {
+			w.Header().Set(name, "1")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1"}`))
+	}))
+	policy := testRetryPolicy()
+	policy.RetryNonIdempotent = true
+	v1.SetRetryPolicy(policy)
+	filePath := filepath.Join(t.TempDir(), "sample.exe")
+	if err := os.WriteFile(filePath, []byte("content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	ctx := context.Background()
+	submit := v1.SandboxSubmitFile()
+	if err := submit.SetReader(ctx, file, "sample.exe"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := submit.Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(contents, ",") != "content,content" {
+		t.Errorf("unexpected uploaded contents: %v", contents)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Errorf("caller's file is closed: %v", err)
+	}
+}
+
+func TestRetryPolicyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	v1 := newTestVOne(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// cancel during backoff after the first attempt
+		time.AfterFunc(50*time.Millisecond, cancel)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	policy := testRetryPolicy()
+	policy.InitialBackoff = time.Minute
+	policy.MaxBackoff = time.Minute
+	v1.SetRetryPolicy(policy)
+	_, err := v1.SandboxDailyReserve().Do(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Errorf("last attempt error is lost: %v", err)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	if err := sleep(context.Background(), time.Millisecond, nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	stop := make(chan struct{})
+	close(stop)
+	if err := sleep(context.Background(), time.Hour, stop); !errors.Is(err, ErrStop) {
+		t.Errorf("expected ErrStop, got %v", err)
+	}
+	cause := errors.New("shutdown")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+	if err := sleep(ctx, time.Hour, nil); !errors.Is(err, cause) {
+		t.Errorf("expected %v, got %v", cause, err)
+	}
+}
+
+func TestRetryPolicyShouldRetryMethod(t *testing.T) {
+	err := &HTTPError{Status: http.StatusServiceUnavailable}
+	testCases := []struct {
+		method   string
+		expected bool
+	}{
+		{http.MethodGet, true},
+		{http.MethodDelete, true},
+		{http.MethodPut, true},
+		{http.MethodPost, false},
+		{http.MethodPatch, false},
+	}
+	p := DefaultRetryPolicy()
+	for _, tc := range testCases {
+		if actual := p.ShouldRetry(tc.method, err); actual != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.method, tc.expected, actual)
+		}
+	}
+	p.RetryNonIdempotent = true
+	for _, tc := range testCases {
+		if !p.ShouldRetry(tc.method, err) {
+			t.Errorf("%s: expected retry of non-idempotent request", tc.method)
+		}
+	}
+}
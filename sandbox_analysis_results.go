@@ -72,6 +72,10 @@ func (s *sandboxAnalysisResultsRequest) url() string {
 	return fmt.Sprintf("/v3.0/sandbox/analysisResults/%s", s.id)
 }
 
+func (s *sandboxAnalysisResultsRequest) operation() string {
+	return "SandboxAnalysisResults"
+}
+
 func (f *sandboxAnalysisResultsRequest) responseStruct() any {
 	return &f.response
 }
@@ -0,0 +1,369 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Sandbox API capabilities
+
+	sandbox_archive.go - expand zip and tar.gz archives locally to submit their files one by one
+*/
+
+package vone
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedArchive = errors.New("unsupported archive")
+	ErrArchiveLimit       = errors.New("archive limit exceeded")
+	ErrArchivePassword    = errors.New("wrong archive password")
+)
+
+// ratioCheckThreshold - extracted size below which compression ratio is not checked
+const ratioCheckThreshold = 1 << 20
+
+// zipEncryptedFlag - general purpose bit flag of encrypted zip entries
+const zipEncryptedFlag = 0x1
+
+// zipDataDescriptorFlag - general purpose bit flag of zip entries with data descriptor
+const zipDataDescriptorFlag = 0x8
+
+// ArchiveLimits - protection against archives expanding to huge amount of data
+// (zip bombs). Zero value of any field means no limit
+type ArchiveLimits struct {
+	MaxMembers    int     // Maximum amount of files in archive
+	MaxMemberSize int64   // Maximum size of one extracted file
+	MaxTotalSize  int64   // Maximum size of all extracted files
+	MaxRatio      float64 // Maximum ratio of all extracted files size to archive size
+}
+
+// DefaultArchiveLimits - up to 1000 files of up to 60MB (maximum size of
+// file accepted by sandbox) and up to 1GB and 100x compression ratio in total
+func DefaultArchiveLimits() ArchiveLimits {
+	return ArchiveLimits{
+		MaxMembers:    1000,
+		MaxMemberSize: 60 << 20,
+		MaxTotalSize:  1 << 30,
+		MaxRatio:      100,
+	}
+}
+
+// ArchiveMember - file extracted from archive
+type ArchiveMember struct {
+	Name   string // Path inside archive
+	Path   string // Path of extracted file
+	Size   int64  // Size of extracted file
+	Digest Digest // Hashes of extracted file
+}
+
+// ExpandArchive - extract regular files of zip (including ones protected by
+// traditional zip encryption) or tar.gz archive to folder. Each file is put to
+// separate subfolder under its base name. Password is used only for encrypted
+// zip files. Returns ErrArchiveLimit if any of limits is exceeded
+func ExpandArchive(archivePath, folder, password string, limits ArchiveLimits) ([]ArchiveMember, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	e := &archiveExpander{
+		folder:      folder,
+		password:    password,
+		limits:      limits,
+		archiveSize: info.Size(),
+	}
+	format, err := archiveFormat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "zip":
+		err = e.expandZip(archivePath)
+	case "tar.gz":
+		err = e.expandTarGz(archivePath)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedArchive, archivePath)
+	}
+	return e.members, err
+}
+
+// archiveFormat - detect archive format by its signature
+func archiveFormat(archivePath string) (string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	signature := make([]byte, 4)
+	n, err := io.ReadFull(f, signature)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("%s: %w", archivePath, err)
+	}
+	signature = signature[:n]
+	switch {
+	case bytes.HasPrefix(signature, []byte("PK\x03\x04")), bytes.HasPrefix(signature, []byte("PK\x05\x06")):
+		return "zip", nil
+	case bytes.HasPrefix(signature, []byte{0x1f, 0x8b}):
+		return "tar.gz", nil
+	}
+	return "", nil
+}
+
+type archiveExpander struct {
+	folder      string
+	password    string
+	limits      ArchiveLimits
+	archiveSize int64
+	total       int64
+	members     []ArchiveMember
+}
+
+func (e *archiveExpander) expandZip(archivePath string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", archivePath, err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		if err := e.checkMemberSize(f.Name, int64(f.UncompressedSize64)); err != nil {
+			return err
+		}
+		if err := e.extractZipFile(f); err != nil {
+			return fmt.Errorf("%s: %s: %w", archivePath, f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (e *archiveExpander) extractZipFile(f *zip.File) error {
+	reader, err := openZipFile(f, e.password)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return e.extract(f.Name, reader)
+}
+
+// openZipFile - open zip file decrypting it with password if it is encrypted
+func openZipFile(f *zip.File, password string) (io.ReadCloser, error) {
+	if f.Flags&zipEncryptedFlag == 0 {
+		return f.Open()
+	}
+	if password == "" {
+		return nil, fmt.Errorf("%w: password required", ErrArchivePassword)
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+	check := byte(f.CRC32 >> 24)
+	if f.Flags&zipDataDescriptorFlag != 0 {
+		check = byte(f.ModifiedTime >> 8)
+	}
+	decrypted, err := newZipCryptoReader(raw, password, check)
+	if err != nil {
+		return nil, err
+	}
+	crc := &crcReader{hash: crc32.NewIEEE(), expected: f.CRC32}
+	switch f.Method {
+	case zip.Store:
+		crc.reader = decrypted
+		return io.NopCloser(crc), nil
+	case zip.Deflate:
+		inflater := flate.NewReader(decrypted)
+		crc.reader = inflater
+		return struct {
+			io.Reader
+			io.Closer
+		}{crc, inflater}, nil
+	default:
+		return nil, fmt.Errorf("%w: compression method %d", ErrUnsupportedArchive, f.Method)
+	}
+}
+
+func (e *archiveExpander) expandTarGz(archivePath string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return fmt.Errorf("%s: %w", archivePath, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := e.checkMemberSize(header.Name, header.Size); err != nil {
+			return err
+		}
+		if err := e.extract(header.Name, tr); err != nil {
+			return fmt.Errorf("%s: %s: %w", archivePath, header.Name, err)
+		}
+	}
+}
+
+// checkMemberSize - reject file by size declared in archive before extracting it
+func (e *archiveExpander) checkMemberSize(name string, size int64) error {
+	if e.limits.MaxMemberSize > 0 && size > e.limits.MaxMemberSize {
+		return fmt.Errorf("%w: %s: file size %d is more than %d", ErrArchiveLimit, name, size, e.limits.MaxMemberSize)
+	}
+	return nil
+}
+
+// extract - write file to its own subfolder while hashing it. Sizes are counted
+// on actually extracted data, as sizes in archive headers can not be trusted
+func (e *archiveExpander) extract(name string, reader io.Reader) error {
+	if e.limits.MaxMembers > 0 && len(e.members) >= e.limits.MaxMembers {
+		return fmt.Errorf("%w: more than %d files", ErrArchiveLimit, e.limits.MaxMembers)
+	}
+	dir := filepath.Join(e.folder, strconv.Itoa(len(e.members)))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, memberFileName(name))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	limit := int64(-1)
+	if e.limits.MaxMemberSize > 0 {
+		limit = e.limits.MaxMemberSize
+	}
+	if e.limits.MaxTotalSize > 0 && (limit < 0 || e.limits.MaxTotalSize-e.total < limit) {
+		limit = e.limits.MaxTotalSize - e.total
+	}
+	if limit >= 0 {
+		reader = io.LimitReader(reader, limit+1)
+	}
+	hasher := newDigestHash()
+	size, err := io.Copy(io.MultiWriter(f, hasher), reader)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	e.total += size
+	if e.limits.MaxMemberSize > 0 && size > e.limits.MaxMemberSize {
+		return fmt.Errorf("%w: file is bigger than %d", ErrArchiveLimit, e.limits.MaxMemberSize)
+	}
+	if e.limits.MaxTotalSize > 0 && e.total > e.limits.MaxTotalSize {
+		return fmt.Errorf("%w: files are bigger than %d in total", ErrArchiveLimit, e.limits.MaxTotalSize)
+	}
+	if e.limits.MaxRatio > 0 && e.total > ratioCheckThreshold &&
+		float64(e.total) > e.limits.MaxRatio*float64(max(e.archiveSize, 1)) {
+		return fmt.Errorf("%w: compression ratio is more than %g", ErrArchiveLimit, e.limits.MaxRatio)
+	}
+	e.members = append(e.members, ArchiveMember{
+		Name:   name,
+		Path:   path,
+		Size:   size,
+		Digest: hasher.Digest(),
+	})
+	return nil
+}
+
+// memberFileName - base name of archive member safe to be used as file name
+func memberFileName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = name[strings.LastIndex(name, "/")+1:]
+	if name == "" || name == "." || name == ".." {
+		return "file"
+	}
+	return name
+}
+
+// crcReader - return ErrArchivePassword at the end of data if its CRC32
+// does not match. This detects wrong passwords passing header check
+type crcReader struct {
+	reader   io.Reader
+	hash     hash.Hash32
+	expected uint32
+}
+
+func (r *crcReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && r.hash.Sum32() != r.expected {
+		return n, fmt.Errorf("%w: checksum mismatch", ErrArchivePassword)
+	}
+	return n, err
+}
+
+// zipCryptoReader - decrypt traditional PKWARE zip encryption (ZipCrypto)
+type zipCryptoReader struct {
+	reader io.Reader
+	keys   [3]uint32
+}
+
+// newZipCryptoReader - initialize keys with password and check encryption header
+func newZipCryptoReader(reader io.Reader, password string, check byte) (*zipCryptoReader, error) {
+	z := &zipCryptoReader{
+		reader: reader,
+		keys:   [3]uint32{0x12345678, 0x23456789, 0x34567890},
+	}
+	for _, b := range []byte(password) {
+		z.update(b)
+	}
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(z, header); err != nil {
+		return nil, err
+	}
+	if header[11] != check {
+		return nil, ErrArchivePassword
+	}
+	return z, nil
+}
+
+func (z *zipCryptoReader) Read(p []byte) (int, error) {
+	n, err := z.reader.Read(p)
+	for i := range p[:n] {
+		p[i] ^= z.streamByte()
+		z.update(p[i])
+	}
+	return n, err
+}
+
+func (z *zipCryptoReader) update(b byte) {
+	z.keys[0] = crc32Update(z.keys[0], b)
+	z.keys[1] = (z.keys[1]+z.keys[0]&0xff)*134775813 + 1
+	z.keys[2] = crc32Update(z.keys[2], byte(z.keys[1]>>24))
+}
+
+func (z *zipCryptoReader) streamByte() byte {
+	t := uint32(uint16(z.keys[2]) | 2)
+	return byte((t * (t ^ 1)) >> 8)
+}
+
+// crc32Update - raw CRC32 step used by ZipCrypto key schedule
+func crc32Update(crc uint32, b byte) uint32 {
+	return crc32.IEEETable[byte(crc)^b] ^ crc>>8
+}
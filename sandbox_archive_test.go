@@ -0,0 +1,154 @@
+package vone
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestZip(t *testing.T, path string, files map[string][]byte, password string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range files {
+		if password == "" {
+			f, err := w.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Write(data)
+			continue
+		}
+		crc := crc32.ChecksumIEEE(data)
+		f, err := w.CreateRaw(&zip.FileHeader{
+			Name:               name,
+			Flags:              zipEncryptedFlag,
+			Method:             zip.Store,
+			CRC32:              crc,
+			CompressedSize64:   uint64(len(data) + 12),
+			UncompressedSize64: uint64(len(data)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		z := &zipCryptoReader{keys: [3]uint32{0x12345678, 0x23456789, 0x34567890}}
+		for _, b := range []byte(password) {
+			z.update(b)
+		}
+		plain := append(bytes.Repeat([]byte{0x5a}, 11), byte(crc>>24))
+		plain = append(plain, data...)
+		for _, b := range plain {
+			f.Write([]byte{b ^ z.streamByte()})
+			z.update(b)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sha1Hex(data []byte) string {
+	hash := sha1.Sum(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func TestExpandArchiveZip(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"a/sample.exe": []byte("sample content"),
+		"../evil.txt":  []byte("other content"),
+	}
+	for _, password := range []string{"", "infected"} {
+		archivePath := filepath.Join(dir, "samples"+password+".zip")
+		writeTestZip(t, archivePath, files, password)
+		members, err := ExpandArchive(archivePath, t.TempDir(), password, DefaultArchiveLimits())
+		if err != nil {
+			t.Fatalf("%q: %v", password, err)
+		}
+		if len(members) != len(files) {
+			t.Fatalf("%q: expected %d files, got %d", password, len(files), len(members))
+		}
+		for _, member := range members {
+			data, err := os.ReadFile(member.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, files[member.Name]) {
+				t.Errorf("%q: %s: wrong content: %q", password, member.Name, data)
+			}
+			if member.Digest.SHA1 != sha1Hex(data) || member.Size != int64(len(data)) {
+				t.Errorf("%q: %s: wrong digest or size: %v", password, member.Name, member)
+			}
+			if filepath.Base(member.Path) == ".." || filepath.Base(member.Path) == "a" {
+				t.Errorf("%s: wrong path: %s", member.Name, member.Path)
+			}
+		}
+	}
+	archivePath := filepath.Join(dir, "samplesinfected.zip")
+	for _, password := range []string{"", "wrong"} {
+		_, err := ExpandArchive(archivePath, t.TempDir(), password, DefaultArchiveLimits())
+		if !errors.Is(err, ErrArchivePassword) {
+			t.Errorf("%q: expected ErrArchivePassword, got %v", password, err)
+		}
+	}
+}
+
+func TestExpandArchiveTarGz(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755})
+	data := []byte("sample content")
+	tw.WriteHeader(&tar.Header{Name: "dir/sample.exe", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(data))})
+	tw.Write(data)
+	tw.Close()
+	gz.Close()
+	archivePath := filepath.Join(t.TempDir(), "samples.tar.gz")
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	members, err := ExpandArchive(archivePath, t.TempDir(), "", DefaultArchiveLimits())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0].Name != "dir/sample.exe" || members[0].Digest.SHA1 != sha1Hex(data) {
+		t.Errorf("wrong files: %v", members)
+	}
+}
+
+func TestExpandArchiveLimits(t *testing.T) {
+	dir := t.TempDir()
+	bombPath := filepath.Join(dir, "bomb.zip")
+	writeTestZip(t, bombPath, map[string][]byte{"zeros": make([]byte, 4<<20)}, "")
+	manyPath := filepath.Join(dir, "many.zip")
+	writeTestZip(t, manyPath, map[string][]byte{"1": {1}, "2": {2}, "3": {3}}, "")
+	testCases := []struct {
+		path   string
+		limits ArchiveLimits
+	}{
+		{bombPath, ArchiveLimits{MaxRatio: 100}},
+		{bombPath, ArchiveLimits{MaxMemberSize: 1 << 20}},
+		{bombPath, ArchiveLimits{MaxTotalSize: 1 << 20}},
+		{manyPath, ArchiveLimits{MaxMembers: 2}},
+	}
+	for _, tc := range testCases {
+		_, err := ExpandArchive(tc.path, t.TempDir(), "", tc.limits)
+		if !errors.Is(err, ErrArchiveLimit) {
+			t.Errorf("%s %+v: expected ErrArchiveLimit, got %v", filepath.Base(tc.path), tc.limits, err)
+		}
+	}
+	if _, err := ExpandArchive(manyPath, t.TempDir(), "", ArchiveLimits{}); err != nil {
+		t.Errorf("no limits: %v", err)
+	}
+}
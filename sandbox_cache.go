@@ -11,252 +11,218 @@ package vone
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 	"time"
 )
 
 //const layout = "2006-01-02T15:04:05Z"
 
-// Cache - database to cache Analyzer check results
+// CacheArtifact - PDF report or investigation package of cached verdict
+type CacheArtifact struct {
+	Path   string `json:"path"`           // Path of downloaded file
+	SHA256 string `json:"sha256"`         // Hash of file content
+	Data   []byte `json:"data,omitempty"` // File content. Nil if only path is cached. Should not be modified
+}
+
+// NewCacheArtifact - hash file for cache. If keepData is true, its content is
+// kept too, so it can be restored after the file is removed
+func NewCacheArtifact(filePath string, keepData bool) (*CacheArtifact, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(data)
+	artifact := &CacheArtifact{
+		Path:   filePath,
+		SHA256: hex.EncodeToString(hash[:]),
+	}
+	if keepData {
+		artifact.Data = data
+	}
+	return artifact, nil
+}
+
+// Restore - return path of file with artifact content: original path if the
+// file is not changed, otherwise cached content is written to filePath.
+// Returns ErrNotFound if the file is changed and content is not cached
+func (a *CacheArtifact) Restore(filePath string) (string, error) {
+	if data, err := os.ReadFile(a.Path); err == nil {
+		hash := sha256.Sum256(data)
+		if strings.EqualFold(hex.EncodeToString(hash[:]), a.SHA256) {
+			return a.Path, nil
+		}
+	}
+	if a.Data == nil {
+		return "", fmt.Errorf("%s: %w", a.Path, ErrNotFound)
+	}
+	if err := os.WriteFile(filePath, a.Data, 0600); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+// CacheEntry - cached verdict along with its indicators and artifacts
+type CacheEntry struct {
+	Result               *SandboxAnalysisResultsResponseItem // Analysis results. Result.ID is analysis ID
+	SuspiciousObjects    []SandboxSuspiciousObject           // Suspicious objects of analysis
+	Report               *CacheArtifact                      // PDF report. Can be nil
+	InvestigationPackage *CacheArtifact                      // Investigation package. Can be nil
+	Updated              time.Time                           // Time entry was added. Set by backend
+}
+
+// clone - copy of entry not sharing verdict and slices (except artifacts data) with original
+func (e *CacheEntry) clone() *CacheEntry {
+	entry := *e
+	result := *e.Result
+	result.DetectionNames = slices.Clone(e.Result.DetectionNames)
+	result.ThreatTypes = slices.Clone(e.Result.ThreatTypes)
+	entry.Result = &result
+	entry.SuspiciousObjects = slices.Clone(e.SuspiciousObjects)
+	if e.Report != nil {
+		report := *e.Report
+		entry.Report = &report
+	}
+	if e.InvestigationPackage != nil {
+		investigationPackage := *e.InvestigationPackage
+		entry.InvestigationPackage = &investigationPackage
+	}
+	return &entry
+}
+
+// CacheBackend - storage of cached sandbox verdicts. Verdicts are identified by SHA1
+type CacheBackend interface {
+	// Add - add or replace entry. Updated field is set by backend
+	Add(ctx context.Context, entry *CacheEntry) error
+	// Query - return entry for SHA1. Nil if verdict is not cached
+	Query(ctx context.Context, sha1 string) (*CacheEntry, error)
+	// Delete - remove entry for SHA1
+	Delete(ctx context.Context, sha1 string) error
+	// Cleanup - remove entries added before given time
+	Cleanup(ctx context.Context, date time.Time) error
+	// Count - return amount of cached entries
+	Count(ctx context.Context) (int, error)
+	// Iterate - call f for each cached entry. Error returned by f stops iteration
+	Iterate(ctx context.Context, f func(entry *CacheEntry) error) error
+	// Close - release resources of backend
+	Close() error
+}
+
+var (
+	_ CacheBackend = &SQLCacheBackend{}
+	_ CacheBackend = &LRUCacheBackend{}
+	_ CacheBackend = &TieredCacheBackend{}
+	_ CacheBackend = &BoltCacheBackend{}
+)
+
+// Cache - cache of Analyzer check results kept by CacheBackend
 type Cache struct {
-	dbPath string
-	db     *sql.DB
+	backend CacheBackend
 }
 
-// NewCache - open existing or create new cache
+// NewCache - open existing or create new cache in SQL database. SQL dialect is
+// detected by database driver. Use NewSQLCacheBackend to set it explicitly
 func NewCache(db *sql.DB, dbPath string) (*Cache, error) {
-	stmt := `CREATE TABLE IF NOT EXISTS hashes (
-		type TEXT NOT NULL,
-		md5 TEXT NOT NULL UNIQUE,
-		sha1 TEXT NOT NULL UNIQUE,
-		sha256 TEXT NOT NULL UNIQUE,
-		arguments TEXT,
-		AnalysisCompletionDateTime TEXT NOT NULL,
-		RiskLevel INTEGER,
-		DetectionNames TEXT,
-		ThreatTypes TEXT,
-		TrueFileType TEXT,
-		updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
-		);
-		CREATE UNIQUE INDEX IF NOT EXISTS hash_idx ON hashes (sha1);
-		`
-	if _, err := db.Exec(stmt); err != nil {
-		return nil, fmt.Errorf("%s: %w", dbPath, err)
+	backend, err := NewSQLCacheBackend(db, DetectSQLDialect(db), dbPath)
+	if err != nil {
+		return nil, err
 	}
+	return NewCacheWithBackend(backend), nil
+}
+
+// NewCacheWithBackend - create cache using given backend
+func NewCacheWithBackend(backend CacheBackend) *Cache {
 	return &Cache{
-		dbPath: dbPath,
-		db:     db,
-	}, nil
+		backend: backend,
+	}
 }
 
-// Add - add Analyzer check result to cache database
-func (c *Cache) Add(ctx context.Context, data *SandboxAnalysisResultsResponseItem) error {
-	stmt := `INSERT OR REPLACE INTO hashes (
-		type,
-		md5,
-		sha1,
-		sha256,
-		arguments,
-		AnalysisCompletionDateTime,
-		RiskLevel,
-		DetectionNames,
-		ThreatTypes,
-		TrueFileType 
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+// Backend - return backend of cache
+func (c *Cache) Backend() CacheBackend {
+	return c.backend
+}
 
-	_, err := c.db.ExecContext(ctx, stmt,
-		data.Type,
-		strings.ToUpper(data.Digest.MD5),
-		strings.ToUpper(data.Digest.SHA1),
-		strings.ToUpper(data.Digest.SHA256),
-		data.Arguments,
-		data.AnalysisCompletionDateTime.String(),
-		data.RiskLevel,
-		strings.Join(data.DetectionNames, ","),
-		strings.Join(data.ThreatTypes, ","),
-		data.TrueFileType)
+// Add - add Analyzer check result to cache
+func (c *Cache) Add(ctx context.Context, data *SandboxAnalysisResultsResponseItem) error {
+	return c.backend.Add(ctx, &CacheEntry{Result: data})
+}
 
-	if err != nil && strings.Contains(err.Error(), "pq: syntax error") {
-		// its postresql
-		stmt := `INSERT INTO hashes (type,
-		md5,
-		sha1,
-		sha256,
-		arguments,
-		AnalysisCompletionDateTime,
-		RiskLevel,
-		DetectionNames,
-		ThreatTypes,
-		TrueFileType
-		) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT (sha1) DO UPDATE SET
-		arguments=$5,
-		AnalysisCompletionDateTime=$6,
-		RiskLevel=$7,
-		DetectionNames=$8,
-		ThreatTypes=$9,
-		TrueFileType=$10`
-		_, err = c.db.Exec(stmt,
-			data.Type,
-			strings.ToUpper(data.Digest.MD5),
-			strings.ToUpper(data.Digest.SHA1),
-			strings.ToUpper(data.Digest.SHA256),
-			data.Arguments,
-			data.AnalysisCompletionDateTime.String(),
-			data.RiskLevel,
-			strings.Join(data.DetectionNames, ","),
-			strings.Join(data.ThreatTypes, ","),
-			data.TrueFileType,
-		)
-	}
-	return c.error("Add Exec", err)
+// AddEntry - add Analyzer check result with suspicious objects and artifacts to cache
+func (c *Cache) AddEntry(ctx context.Context, entry *CacheEntry) error {
+	return c.backend.Add(ctx, entry)
 }
 
-// Delete - delete entity from hashes table
+// Delete - delete entity from cache
 func (c *Cache) Delete(sha1 string) error {
-	stmt := "DELETE FROM hashes where sha1=$1"
-	_, err := c.db.Exec(stmt, strings.ToUpper(sha1))
-	return c.error("Delete Exec", err)
+	return c.DeleteContext(context.Background(), sha1)
+}
+
+// DeleteContext - delete entity from cache
+func (c *Cache) DeleteContext(ctx context.Context, sha1 string) error {
+	return c.backend.Delete(ctx, sha1)
 }
 
 // Cleanup - remove data from cache that was put there before
 // time provided
 func (c *Cache) Cleanup(ctx context.Context, date time.Time) error {
-	//	stmt := "DELETE FROM hashes where strftime('%s', updated) < $1"
-	//	_, err := c.DB.Exec(stmt, fmt.Sprint(date.Unix()))
-	stmt := "DELETE FROM hashes where updated < $1"
-	if _, err := c.db.ExecContext(ctx, stmt, date); err != nil {
-		return c.error("Cleanup hashes", err)
-	}
-	return nil
+	return c.backend.Cleanup(ctx, date)
 }
 
 var ErrNotFound = errors.New("not found")
 
 // Query - get cached Analyzer check result for SHA1 of file
 func (c *Cache) Query(ctx context.Context, sha1 string) (*SandboxAnalysisResultsResponseItem, time.Time, error) {
-	stmt := `SELECT type,
-		md5,
-		sha1,
-		sha256,
-		arguments,
-		AnalysisCompletionDateTime,
-		RiskLevel,
-		DetectionNames,
-		ThreatTypes,
-		TrueFileType,
-		updated FROM hashes WHERE sha1=$1`
-	rows, err := c.db.QueryContext(ctx, stmt, strings.ToUpper(sha1))
-	if err != nil {
-		return nil, time.Time{}, c.error("Query", err)
-	}
-	defer rows.Close()
-	if rows.Err() != nil {
-		return nil, time.Time{}, c.error("Query", rows.Err())
+	entry, err := c.backend.Query(ctx, sha1)
+	if err != nil || entry == nil {
+		return nil, time.Time{}, err
 	}
-	if !rows.Next() {
-		return nil, time.Time{}, nil // fmt.Errorf("Query %s: %w", sha1, ErrNotFound)
-	}
-	return c.ScanSandboxAnalysisResultsResponse(rows)
+	return entry.Result, entry.Updated, nil
 }
 
-func (c *Cache) ScanSandboxAnalysisResultsResponse(rows *sql.Rows) (*SandboxAnalysisResultsResponseItem, time.Time, error) {
-	var data SandboxAnalysisResultsResponseItem
-	var detectionNames, threatTypes string
-	var analysisCompletionDateTime string
-	var updated string
-	err := rows.Scan(&data.Type, &data.Digest.MD5, &data.Digest.SHA1, &data.Digest.SHA256,
-		&data.Arguments, &analysisCompletionDateTime, &data.RiskLevel, &detectionNames,
-		&threatTypes, &data.TrueFileType, &updated)
-	if err != nil {
-		return nil, time.Time{}, c.error("Query row.Scan", err)
-	}
-	t, err := time.Parse(timeFormat, analysisCompletionDateTime)
-	if err != nil {
-		t, err = time.Parse(timeFormatZ, analysisCompletionDateTime)
-		if err != nil {
-			return nil, time.Time{}, fmt.Errorf("ScanSandboxAnalysisResults Parse \"%s\": %w",
-				analysisCompletionDateTime, err)
-		}
-	}
-	data.AnalysisCompletionDateTime = VisionOneTime(t)
-
-	data.DetectionNames = strings.Split(detectionNames, ",")
-	data.ThreatTypes = strings.Split(threatTypes, ",")
-	date, err := time.Parse(timeFormatZ, updated)
-	if err != nil {
-		return nil, time.Time{}, c.error("IterateCache time.Parse \""+updated+"\"", err)
-	}
-	return &data, date, nil
+// QueryEntry - get cached Analyzer check result with suspicious objects and
+// artifacts for SHA1 of file. Nil if file is not cached
+func (c *Cache) QueryEntry(ctx context.Context, sha1 string) (*CacheEntry, error) {
+	return c.backend.Query(ctx, sha1)
 }
 
-// Count - return number of entities in cache database
+// Count - return number of entities in cache
 func (c *Cache) Count(ctx context.Context) (int, error) {
-	rows, err := c.db.QueryContext(ctx, "SELECT COUNT(*) FROM hashes")
-	if err != nil {
-		return -1, c.error("Count", err)
-	}
-	if rows.Err() != nil {
-		return 0, c.error("Count", rows.Err())
-	}
-	defer rows.Close()
-	if !rows.Next() {
-		return 0, c.error("Count", fmt.Errorf("rows.Next() returned false"))
-	}
-	var value int
-	err = rows.Scan(&value)
-	if err != nil {
-		return -1, err
-	}
-	return value, nil
+	return c.backend.Count(ctx)
 }
 
-// Close database - should be called when database is not in use anymore
+// Close cache - should be called when cache is not in use anymore
 func (c *Cache) Close() error {
-	return c.db.Close()
+	return c.backend.Close()
 }
 
-// IterateCache - perform provided function fo each database entity
-func (c *Cache) IterateCache(ctx context.Context, f func(data *SandboxAnalysisResultsResponseItem, updated time.Time) error) error {
-	Select := `SELECT
-	    type,
-		md5,
-		sha1,
-		sha256,
-		arguments,
-		AnalysisCompletionDateTime,
-		RiskLevel,
-		DetectionNames,
-		ThreatTypes,
-		TrueFileType,
-		updated
-		FROM hashes`
-	rows, err := c.db.QueryContext(ctx, Select)
-	if err != nil {
-		return c.error("IterateCache Query", err)
-	}
-	defer rows.Close()
-	if rows.Err() != nil {
-		return c.error("IterateCache Query", rows.Err())
-	}
-	for rows.Next() {
-		data, updated, err := c.ScanSandboxAnalysisResultsResponse(rows)
-		if err != nil {
-			return c.error("IterateCache", err)
-		}
-		err = f(data, updated)
-		if err != nil {
-			return c.error("IterateCache callback", err)
-		}
-	}
-	return nil
+// Iterate - perform provided function for each cache entity
+func (c *Cache) Iterate(ctx context.Context, f func(data *SandboxAnalysisResultsResponseItem, updated time.Time) error) error {
+	return c.backend.Iterate(ctx, func(entry *CacheEntry) error {
+		return f(entry.Result, entry.Updated)
+	})
 }
 
-func (c *Cache) error(message string, err error) error {
-	if err == nil {
-		return nil
+// ScanSandboxAnalysisResultsResponse - read verdict from rows selected from
+// hashes table of SQL cache.
+//
+// Deprecated: use Query, Iterate or Select
+func (c *Cache) ScanSandboxAnalysisResultsResponse(rows *sql.Rows) (*SandboxAnalysisResultsResponseItem, time.Time, error) {
+	backend, ok := c.backend.(*SQLCacheBackend)
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("ScanSandboxAnalysisResultsResponse: %T is not SQL cache backend", c.backend)
 	}
-	return fmt.Errorf("%s: %s: %w", c.dbPath, message, err)
+	return backend.ScanSandboxAnalysisResultsResponse(rows)
+}
+
+// IterateCache - perform provided function fo each cache entity.
+//
+// Deprecated: use Iterate
+func (c *Cache) IterateCache(ctx context.Context, f func(data *SandboxAnalysisResultsResponseItem, updated time.Time) error) error {
+	return c.Iterate(ctx, f)
 }
@@ -0,0 +1,176 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	sandbox_cache_bolt.go - bbolt cache backend
+*/
+
+package vone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltCacheBucket - bucket keeping entries as JSON by upper case SHA1
+var boltCacheBucket = []byte("verdicts")
+
+// BoltCacheBackend - cache backend keeping verdicts in bbolt key/value file.
+// Unlike SQLCacheBackend it needs no SQL driver, but bbolt file can be opened
+// by only one process at a time. Usage:
+//
+//	db, err := bolt.Open("cache.bolt", 0600, &bolt.Options{Timeout: 10 * time.Second})
+//	...
+//	backend, err := vone.NewBoltCacheBackend(db)
+//	...
+//	cache := vone.NewCacheWithBackend(backend)
+type BoltCacheBackend struct {
+	db  *bolt.DB
+	now func() time.Time
+}
+
+// NewBoltCacheBackend - create backend in db. Backend owns db and closes it on Close
+func NewBoltCacheBackend(db *bolt.DB) (*BoltCacheBackend, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltCacheBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", db.Path(), err)
+	}
+	return &BoltCacheBackend{
+		db:  db,
+		now: time.Now,
+	}, nil
+}
+
+// Add - add or replace entry
+func (c *BoltCacheBackend) Add(ctx context.Context, entry *CacheEntry) error {
+	record := cacheRecord(*entry)
+	record.Updated = c.now()
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCacheBucket).Put(boltCacheKey(entry.Result.Digest.SHA1), value)
+	})
+}
+
+// Query - return entry for SHA1
+func (c *BoltCacheBackend) Query(ctx context.Context, sha1 string) (*CacheEntry, error) {
+	var entry *CacheEntry
+	err := c.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(boltCacheBucket).Get(boltCacheKey(sha1))
+		if value == nil {
+			return nil
+		}
+		var err error
+		entry, err = decodeBoltCacheEntry(value)
+		return err
+	})
+	return entry, err
+}
+
+// Delete - remove entry for SHA1
+func (c *BoltCacheBackend) Delete(ctx context.Context, sha1 string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCacheBucket).Delete(boltCacheKey(sha1))
+	})
+}
+
+// Cleanup - remove entries added before given time
+func (c *BoltCacheBackend) Cleanup(ctx context.Context, date time.Time) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltCacheBucket).Cursor()
+		for key, value := cursor.First(); key != nil; {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var record struct {
+				Updated time.Time `json:"updated"`
+			}
+			if err := json.Unmarshal(value, &record); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			if record.Updated.Before(date) {
+				// Delete moves cursor to the next key
+				if err := cursor.Delete(); err != nil {
+					return err
+				}
+				key, value = cursor.Seek(key)
+				continue
+			}
+			key, value = cursor.Next()
+		}
+		return nil
+	})
+}
+
+// Count - return amount of entries
+func (c *BoltCacheBackend) Count(ctx context.Context) (int, error) {
+	var count int
+	err := c.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(boltCacheBucket).Stats().KeyN
+		return nil
+	})
+	return count, err
+}
+
+// Iterate - call f for each entry in SHA1 order. Transaction is not kept open
+// while f is running, so f can change cache
+func (c *BoltCacheBackend) Iterate(ctx context.Context, f func(entry *CacheEntry) error) error {
+	var keys []string
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCacheBucket).ForEach(func(key, _ []byte) error {
+			keys = append(keys, string(key))
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry, err := c.Query(ctx, key)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			// removed after keys were collected
+			continue
+		}
+		if err := f(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close - close bbolt database
+func (c *BoltCacheBackend) Close() error {
+	return c.db.Close()
+}
+
+// boltCacheKey - key of entry for SHA1
+func boltCacheKey(sha1 string) []byte {
+	return []byte(strings.ToUpper(sha1))
+}
+
+// decodeBoltCacheEntry - entry from stored JSON. Value is copied, so entry
+// can be used after transaction is closed
+func decodeBoltCacheEntry(value []byte) (*CacheEntry, error) {
+	var record cacheRecord
+	if err := json.Unmarshal(value, &record); err != nil {
+		return nil, err
+	}
+	entry := CacheEntry(record)
+	return &entry, nil
+}
@@ -0,0 +1,80 @@
+package vone
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestBoltCacheBackend(t *testing.T) *BoltCacheBackend {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "cache.bolt"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend, err := NewBoltCacheBackend(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backend
+}
+
+func TestBoltCacheBackend(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	backend := newTestBoltCacheBackend(t)
+	defer backend.Close()
+	backend.now = func() time.Time { return now }
+	entry := &CacheEntry{
+		Result:            testCacheResult("a"),
+		SuspiciousObjects: []SandboxSuspiciousObject{{RiskLevel: RiskLevelHigh, RootSHA1: "a", URL: "http://evil.example.com"}},
+		Report:            &CacheArtifact{Path: "report.pdf", SHA256: "1234", Data: []byte("%PDF")},
+	}
+	for _, sha1 := range []string{"c", "b"} {
+		if err := backend.Add(ctx, &CacheEntry{Result: testCacheResult(sha1)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now = now.Add(time.Hour)
+	if err := backend.Add(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	got, err := backend.Query(ctx, "A")
+	if err != nil || got == nil {
+		t.Fatalf("a is not found: %v", err)
+	}
+	if !got.Updated.Equal(now) || got.Result.RiskLevel != RiskLevelHigh ||
+		len(got.SuspiciousObjects) != 1 || string(got.Report.Data) != "%PDF" {
+		t.Errorf("wrong a: %+v", got)
+	}
+	if got, err := backend.Query(ctx, "d"); err != nil || got != nil {
+		t.Errorf("expected no d, got %v, %v", got, err)
+	}
+	var order []string
+	backend.Iterate(ctx, func(entry *CacheEntry) error {
+		order = append(order, entry.Result.Digest.SHA1)
+		// changing cache while iterating should not dead lock
+		return backend.Delete(ctx, "b")
+	})
+	if len(order) != 2 || order[0] != "a" || order[1] != "c" {
+		t.Errorf("wrong order: %v", order)
+	}
+	if err := backend.Add(ctx, &CacheEntry{Result: testCacheResult("b")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Cleanup(ctx, now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := backend.Count(ctx); count != 2 {
+		t.Errorf("expected 2 verdicts after cleanup, got %d", count)
+	}
+	if got, _ := backend.Query(ctx, "c"); got != nil {
+		t.Errorf("c is not cleaned up")
+	}
+	backend.Delete(ctx, "A")
+	if count, _ := backend.Count(ctx); count != 1 {
+		t.Errorf("expected 1 verdict after delete, got %d", count)
+	}
+}
@@ -0,0 +1,378 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	sandbox_cache_export.go - export and import of cached verdicts
+*/
+
+package vone
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CacheFormat - format of exported cache entries
+type CacheFormat int
+
+const (
+	CacheFormatJSONL CacheFormat = iota // One JSON object per line
+	CacheFormatCSV                      // CSV with header. Suspicious objects and artifacts are JSON encoded
+)
+
+var ErrUnknownCacheFormat = errors.New("unknown cache format")
+
+// String - return name of format
+func (f CacheFormat) String() string {
+	switch f {
+	case CacheFormatJSONL:
+		return "jsonl"
+	case CacheFormatCSV:
+		return "csv"
+	default:
+		return fmt.Sprintf("CacheFormat(%d)", int(f))
+	}
+}
+
+// ParseCacheFormat - return format by its name (jsonl or csv)
+func ParseCacheFormat(name string) (CacheFormat, error) {
+	switch strings.ToLower(name) {
+	case "jsonl":
+		return CacheFormatJSONL, nil
+	case "csv":
+		return CacheFormatCSV, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCacheFormat, name)
+	}
+}
+
+// CacheConflict - what to do with imported entry if cache already has verdict for the same SHA1
+type CacheConflict int
+
+const (
+	CacheConflictNewest    CacheConflict = iota // Keep entry with later analysis completion time
+	CacheConflictOverwrite                      // Replace cached entry with imported one
+	CacheConflictSkip                           // Keep cached entry
+)
+
+var ErrUnknownCacheConflict = errors.New("unknown cache conflict policy")
+
+// String - return name of conflict policy
+func (c CacheConflict) String() string {
+	switch c {
+	case CacheConflictNewest:
+		return "newest"
+	case CacheConflictOverwrite:
+		return "overwrite"
+	case CacheConflictSkip:
+		return "skip"
+	default:
+		return fmt.Sprintf("CacheConflict(%d)", int(c))
+	}
+}
+
+// ParseCacheConflict - return conflict policy by its name (newest, overwrite or skip)
+func ParseCacheConflict(name string) (CacheConflict, error) {
+	switch strings.ToLower(name) {
+	case "newest":
+		return CacheConflictNewest, nil
+	case "overwrite":
+		return CacheConflictOverwrite, nil
+	case "skip":
+		return CacheConflictSkip, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCacheConflict, name)
+	}
+}
+
+// CacheImportStats - result of cache import
+type CacheImportStats struct {
+	Added    int // Entries for files that were not cached
+	Replaced int // Cached entries replaced by imported ones
+	Skipped  int // Imported entries ignored due to conflict policy
+}
+
+// cacheRecord - exported cache entry
+type cacheRecord struct {
+	Result               *SandboxAnalysisResultsResponseItem `json:"result"`
+	SuspiciousObjects    []SandboxSuspiciousObject           `json:"suspiciousObjects,omitempty"`
+	Report               *CacheArtifact                      `json:"report,omitempty"`
+	InvestigationPackage *CacheArtifact                      `json:"investigationPackage,omitempty"`
+	Updated              time.Time                           `json:"updated"`
+}
+
+// Export - write all cached entries to w in given format. Artifacts content is
+// exported only if it is cached (see CachingAnalyzer.KeepArtifacts)
+func (c *Cache) Export(ctx context.Context, w io.Writer, format CacheFormat) (int, error) {
+	var write func(*CacheEntry) error
+	var flush func() error
+	switch format {
+	case CacheFormatJSONL:
+		encoder := json.NewEncoder(w)
+		write = func(entry *CacheEntry) error {
+			return encoder.Encode(cacheRecord(*entry))
+		}
+		flush = func() error { return nil }
+	case CacheFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(cacheCSVColumns); err != nil {
+			return 0, err
+		}
+		write = func(entry *CacheEntry) error {
+			row, err := cacheCSVRow(entry)
+			if err != nil {
+				return err
+			}
+			return writer.Write(row)
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	default:
+		return 0, fmt.Errorf("%w: %v", ErrUnknownCacheFormat, format)
+	}
+	count := 0
+	err := c.backend.Iterate(ctx, func(entry *CacheEntry) error {
+		if err := write(entry); err != nil {
+			return fmt.Errorf("export %s: %w", entry.Result.Digest.SHA1, err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, flush()
+}
+
+// Import - add entries read from r in given format to cache. Entries for
+// already cached files are handled according to conflict policy. Update time
+// of imported entries is set by cache backend, so they expire as if they were
+// just analyzed
+func (c *Cache) Import(ctx context.Context, r io.Reader, format CacheFormat, conflict CacheConflict) (*CacheImportStats, error) {
+	stats := &CacheImportStats{}
+	switch conflict {
+	case CacheConflictNewest, CacheConflictOverwrite, CacheConflictSkip:
+	default:
+		return stats, fmt.Errorf("%w: %v", ErrUnknownCacheConflict, conflict)
+	}
+	add := func(entry *CacheEntry) error {
+		if entry.Result == nil || entry.Result.Digest.SHA1 == "" {
+			return errors.New("missing SHA1")
+		}
+		existing, err := c.backend.Query(ctx, entry.Result.Digest.SHA1)
+		if err != nil {
+			return err
+		}
+		if existing != nil && !conflict.replace(existing, entry) {
+			stats.Skipped++
+			return nil
+		}
+		if err := c.backend.Add(ctx, entry); err != nil {
+			return err
+		}
+		if existing != nil {
+			stats.Replaced++
+		} else {
+			stats.Added++
+		}
+		return nil
+	}
+	switch format {
+	case CacheFormatJSONL:
+		return stats, importJSONL(r, add)
+	case CacheFormatCSV:
+		return stats, importCSV(r, add)
+	default:
+		return stats, fmt.Errorf("%w: %v", ErrUnknownCacheFormat, format)
+	}
+}
+
+// replace - check whether imported entry should replace cached one
+func (c CacheConflict) replace(existing, imported *CacheEntry) bool {
+	switch c {
+	case CacheConflictOverwrite:
+		return true
+	case CacheConflictNewest:
+		return time.Time(imported.Result.AnalysisCompletionDateTime).After(time.Time(existing.Result.AnalysisCompletionDateTime))
+	default:
+		return false
+	}
+}
+
+// importJSONL - call add for each JSON object of r
+func importJSONL(r io.Reader, add func(*CacheEntry) error) error {
+	decoder := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		var record cacheRecord
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("record %d: %w", n, err)
+		}
+		entry := CacheEntry(record)
+		if err := add(&entry); err != nil {
+			return fmt.Errorf("record %d: %w", n, err)
+		}
+	}
+}
+
+// cacheCSVColumns - header of exported CSV
+var cacheCSVColumns = []string{
+	"type",
+	"md5",
+	"sha1",
+	"sha256",
+	"id",
+	"arguments",
+	"analysisCompletionDateTime",
+	"riskLevel",
+	"detectionNames",
+	"threatTypes",
+	"trueFileType",
+	"updated",
+	"suspiciousObjects",
+	"report",
+	"investigationPackage",
+}
+
+// cacheCSVRow - entry as CSV row in the order of cacheCSVColumns. Detection
+// names and threat types are comma separated like in SQL cache
+func cacheCSVRow(entry *CacheEntry) ([]string, error) {
+	data := entry.Result
+	var suspiciousObjects []byte
+	if len(entry.SuspiciousObjects) > 0 {
+		var err error
+		if suspiciousObjects, err = json.Marshal(entry.SuspiciousObjects); err != nil {
+			return nil, err
+		}
+	}
+	report, err := marshalCacheArtifact(entry.Report)
+	if err != nil {
+		return nil, err
+	}
+	investigationPackage, err := marshalCacheArtifact(entry.InvestigationPackage)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		data.Type,
+		data.Digest.MD5,
+		data.Digest.SHA1,
+		data.Digest.SHA256,
+		data.ID,
+		data.Arguments,
+		data.AnalysisCompletionDateTime.String(),
+		data.RiskLevel.String(),
+		strings.Join(data.DetectionNames, ","),
+		strings.Join(data.ThreatTypes, ","),
+		data.TrueFileType,
+		entry.Updated.UTC().Format(time.RFC3339),
+		string(suspiciousObjects),
+		report,
+		investigationPackage,
+	}, nil
+}
+
+// marshalCacheArtifact - artifact as JSON. Empty string for nil artifact
+func marshalCacheArtifact(artifact *CacheArtifact) (string, error) {
+	if artifact == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(artifact)
+	return string(data), err
+}
+
+// importCSV - call add for each row of r. Columns are matched by header, so
+// their order can differ from cacheCSVColumns and missing ones are left empty
+func importCSV(r io.Reader, add func(*CacheEntry) error) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("header: %w", err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		index[name] = i
+	}
+	if _, ok := index["sha1"]; !ok {
+		return errors.New("header: missing sha1 column")
+	}
+	reader.FieldsPerRecord = len(header)
+	for n := 1; ; n++ {
+		row, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("row %d: %w", n, err)
+		}
+		column := func(name string) string {
+			if i, ok := index[name]; ok {
+				return row[i]
+			}
+			return ""
+		}
+		entry, err := parseCacheCSVRow(column)
+		if err == nil {
+			err = add(entry)
+		}
+		if err != nil {
+			return fmt.Errorf("row %d: %w", n, err)
+		}
+	}
+}
+
+// parseCacheCSVRow - entry from CSV row columns
+func parseCacheCSVRow(column func(name string) string) (*CacheEntry, error) {
+	data := &SandboxAnalysisResultsResponseItem{
+		Type: column("type"),
+		Digest: Digest{
+			MD5:    column("md5"),
+			SHA1:   column("sha1"),
+			SHA256: column("sha256"),
+		},
+		ID:           column("id"),
+		Arguments:    column("arguments"),
+		TrueFileType: column("trueFileType"),
+	}
+	var err error
+	if data.AnalysisCompletionDateTime, err = parseVisionOneTime(column("analysisCompletionDateTime")); err != nil {
+		return nil, fmt.Errorf("analysisCompletionDateTime: %w", err)
+	}
+	riskLevel, ok := MapRiskLevelFromString[strings.ToLower(column("riskLevel"))]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRiskLevel, column("riskLevel"))
+	}
+	data.RiskLevel = riskLevel
+	if value := column("detectionNames"); value != "" {
+		data.DetectionNames = strings.Split(value, ",")
+	}
+	if value := column("threatTypes"); value != "" {
+		data.ThreatTypes = strings.Split(value, ",")
+	}
+	entry := &CacheEntry{Result: data}
+	for name, target := range map[string]any{
+		"suspiciousObjects":    &entry.SuspiciousObjects,
+		"report":               &entry.Report,
+		"investigationPackage": &entry.InvestigationPackage,
+	} {
+		if value := column(name); value != "" {
+			if err := json.Unmarshal([]byte(value), target); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,154 @@
+package vone
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestCacheExportImport(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "cache.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := NewCache(db, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	result := testCacheResult("AAAA")
+	result.ID = "analysis-a"
+	entry := &CacheEntry{
+		Result: result,
+		SuspiciousObjects: []SandboxSuspiciousObject{{
+			RiskLevel: RiskLevelHigh,
+			RootSHA1:  "AAAA",
+			Domain:    "evil.example.com",
+		}},
+		Report:               &CacheArtifact{Path: "report.pdf", SHA256: "1234", Data: []byte("%PDF,\"report\"\n")},
+		InvestigationPackage: &CacheArtifact{Path: "package.zip", SHA256: "5678"},
+	}
+	if err := source.AddEntry(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.Add(ctx, testCacheResult("BBBB")); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []CacheFormat{CacheFormatJSONL, CacheFormatCSV} {
+		t.Run(format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			count, err := source.Export(ctx, &buf, format)
+			if err != nil || count != 2 {
+				t.Fatalf("export: %d, %v", count, err)
+			}
+			target := NewCacheWithBackend(NewLRUCacheBackend(0))
+			stats, err := target.Import(ctx, bytes.NewReader(buf.Bytes()), format, CacheConflictNewest)
+			if err != nil || *stats != (CacheImportStats{Added: 2}) {
+				t.Fatalf("import: %v, %v", stats, err)
+			}
+			imported, err := target.QueryEntry(ctx, "AAAA")
+			if err != nil || imported == nil {
+				t.Fatalf("AAAA is not imported: %v", err)
+			}
+			if imported.Result.ID != "analysis-a" ||
+				imported.Result.RiskLevel != RiskLevelHigh ||
+				!time.Time(imported.Result.AnalysisCompletionDateTime).Equal(time.Time(result.AnalysisCompletionDateTime)) ||
+				!slices.Equal(imported.Result.DetectionNames, result.DetectionNames) {
+				t.Errorf("wrong result: %v", imported.Result)
+			}
+			if !slices.Equal(imported.SuspiciousObjects, entry.SuspiciousObjects) {
+				t.Errorf("wrong suspicious objects: %v", imported.SuspiciousObjects)
+			}
+			if imported.Report == nil || string(imported.Report.Data) != string(entry.Report.Data) {
+				t.Errorf("wrong report: %v", imported.Report)
+			}
+			if imported.InvestigationPackage == nil || imported.InvestigationPackage.Data != nil || imported.InvestigationPackage.SHA256 != "5678" {
+				t.Errorf("wrong investigation package: %v", imported.InvestigationPackage)
+			}
+			stats, err = target.Import(ctx, bytes.NewReader(buf.Bytes()), format, CacheConflictSkip)
+			if err != nil || *stats != (CacheImportStats{Skipped: 2}) {
+				t.Errorf("import again: %v, %v", stats, err)
+			}
+		})
+	}
+}
+
+func TestCacheImportConflict(t *testing.T) {
+	ctx := context.Background()
+	at := func(sha1 string, hour int) *SandboxAnalysisResultsResponseItem {
+		result := testCacheResult(sha1)
+		result.AnalysisCompletionDateTime = VisionOneTime(time.Date(2026, 1, 10, hour, 0, 0, 0, time.UTC))
+		result.TrueFileType = strings.Repeat("x", hour)
+		return result
+	}
+	exported := NewCacheWithBackend(NewLRUCacheBackend(0))
+	exported.Add(ctx, at("A", 1))
+	exported.Add(ctx, at("B", 3))
+	exported.Add(ctx, at("C", 2))
+	var buf bytes.Buffer
+	if _, err := exported.Export(ctx, &buf, CacheFormatJSONL); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		conflict CacheConflict
+		expected CacheImportStats
+		hours    []int
+	}{
+		{CacheConflictNewest, CacheImportStats{Added: 1, Replaced: 1, Skipped: 1}, []int{2, 3, 2}},
+		{CacheConflictOverwrite, CacheImportStats{Added: 1, Replaced: 2}, []int{1, 3, 2}},
+		{CacheConflictSkip, CacheImportStats{Added: 1, Skipped: 2}, []int{2, 2, 2}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.conflict.String(), func(t *testing.T) {
+			cache := NewCacheWithBackend(NewLRUCacheBackend(0))
+			cache.Add(ctx, at("A", 2))
+			cache.Add(ctx, at("B", 2))
+			stats, err := cache.Import(ctx, bytes.NewReader(buf.Bytes()), CacheFormatJSONL, tc.conflict)
+			if err != nil || *stats != tc.expected {
+				t.Fatalf("expected %v, got %v, %v", tc.expected, stats, err)
+			}
+			for i, sha1 := range []string{"A", "B", "C"} {
+				data, _, _ := cache.Query(ctx, sha1)
+				if len(data.TrueFileType) != tc.hours[i] {
+					t.Errorf("%s: expected verdict of hour %d, got %s", sha1, tc.hours[i], data.AnalysisCompletionDateTime)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheImportErrors(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCacheWithBackend(NewLRUCacheBackend(0))
+	if _, err := ParseCacheFormat("xml"); !errors.Is(err, ErrUnknownCacheFormat) {
+		t.Errorf("expected ErrUnknownCacheFormat, got %v", err)
+	}
+	if _, err := ParseCacheConflict("merge"); !errors.Is(err, ErrUnknownCacheConflict) {
+		t.Errorf("expected ErrUnknownCacheConflict, got %v", err)
+	}
+	testCases := []struct {
+		format CacheFormat
+		input  string
+	}{
+		{CacheFormatJSONL, `{"result":{"digest":{"sha1":"A"}}}` + "\n" + `{"result":{"digest":{}}}`},
+		{CacheFormatJSONL, `{"result":`},
+		{CacheFormatCSV, "md5,sha256\nx,y\n"},
+		{CacheFormatCSV, "sha1,riskLevel,analysisCompletionDateTime\nA,extreme,2026-01-10T00:00:00Z\n"},
+		{CacheFormatCSV, "sha1,riskLevel,analysisCompletionDateTime,report\nA,high,2026-01-10T00:00:00Z,{\n"},
+	}
+	for i, tc := range testCases {
+		if _, err := cache.Import(ctx, strings.NewReader(tc.input), tc.format, CacheConflictOverwrite); err == nil {
+			t.Errorf("%d: error expected", i)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	sandbox_cache_lru.go - in-memory cache backends
+*/
+
+package vone
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRUCacheBackend - cache backend keeping verdicts in memory. When amount of
+// verdicts reaches capacity, least recently used one is evicted
+type LRUCacheBackend struct {
+	capacity int
+	mu       sync.Mutex
+	order    *list.List               // of *CacheEntry, most recently used first
+	index    map[string]*list.Element // by upper case SHA1
+	now      func() time.Time
+}
+
+// NewLRUCacheBackend - create in-memory backend keeping up to capacity verdicts.
+// Zero or negative capacity means no limit
+func NewLRUCacheBackend(capacity int) *LRUCacheBackend {
+	return &LRUCacheBackend{
+		capacity: capacity,
+		order:    list.New(),
+		index:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Add - add or replace entry
+func (c *LRUCacheBackend) Add(ctx context.Context, entry *CacheEntry) error {
+	entry = entry.clone()
+	entry.Updated = c.now()
+	c.put(entry)
+	return nil
+}
+
+// put - add entry keeping its Updated time. Entry should not be used by caller afterwards
+func (c *LRUCacheBackend) put(entry *CacheEntry) {
+	key := strings.ToUpper(entry.Result.Digest.SHA1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.index[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.index[key] = c.order.PushFront(entry)
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// Query - return entry for SHA1 marking it as recently used
+func (c *LRUCacheBackend) Query(ctx context.Context, sha1 string) (*CacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.index[strings.ToUpper(sha1)]
+	if !ok {
+		return nil, nil
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*CacheEntry).clone(), nil
+}
+
+// Delete - remove entry for SHA1
+func (c *LRUCacheBackend) Delete(ctx context.Context, sha1 string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.index[strings.ToUpper(sha1)]; ok {
+		c.remove(element)
+	}
+	return nil
+}
+
+// Cleanup - remove entries added before given time
+func (c *LRUCacheBackend) Cleanup(ctx context.Context, date time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*CacheEntry).Updated.Before(date) {
+			c.remove(element)
+		}
+		element = next
+	}
+	return nil
+}
+
+// Count - return amount of entries in memory
+func (c *LRUCacheBackend) Count(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len(), nil
+}
+
+// Iterate - call f for each entry from most to least recently used.
+// Iteration does not change order of entries
+func (c *LRUCacheBackend) Iterate(ctx context.Context, f func(entry *CacheEntry) error) error {
+	c.mu.Lock()
+	entries := make([]*CacheEntry, 0, c.order.Len())
+	for element := c.order.Front(); element != nil; element = element.Next() {
+		entries = append(entries, element.Value.(*CacheEntry))
+	}
+	c.mu.Unlock()
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := f(entry.clone()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close - drop all entries
+func (c *LRUCacheBackend) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.index)
+	return nil
+}
+
+// remove - should be called with mu locked
+func (c *LRUCacheBackend) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*CacheEntry)
+	delete(c.index, strings.ToUpper(entry.Result.Digest.SHA1))
+}
+
+// TieredCacheBackend - in-memory LRU in front of persistent backend (like
+// SQLCacheBackend). Lookups hitting the front do not reach the back backend,
+// verdicts found in the back backend are promoted to the front. Usage:
+//
+//	sqlBackend, err := vone.NewSQLCacheBackend(db, vone.SQLDialectPostgreSQL, dsn)
+//	...
+//	cache := vone.NewCacheWithBackend(vone.NewTieredCacheBackend(vone.NewLRUCacheBackend(10000), sqlBackend))
+type TieredCacheBackend struct {
+	Front *LRUCacheBackend
+	Back  CacheBackend
+}
+
+// NewTieredCacheBackend - create backend with front LRU and back persistent backends
+func NewTieredCacheBackend(front *LRUCacheBackend, back CacheBackend) *TieredCacheBackend {
+	return &TieredCacheBackend{
+		Front: front,
+		Back:  back,
+	}
+}
+
+// Add - add entry to both backends
+func (c *TieredCacheBackend) Add(ctx context.Context, entry *CacheEntry) error {
+	if err := c.Back.Add(ctx, entry); err != nil {
+		return err
+	}
+	return c.Front.Add(ctx, entry)
+}
+
+// Query - return entry from front backend or from back one if it is missing in front
+func (c *TieredCacheBackend) Query(ctx context.Context, sha1 string) (*CacheEntry, error) {
+	entry, err := c.Front.Query(ctx, sha1)
+	if err != nil || entry != nil {
+		return entry, err
+	}
+	entry, err = c.Back.Query(ctx, sha1)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+	c.Front.put(entry.clone())
+	return entry, nil
+}
+
+// Delete - remove verdict from both backends
+func (c *TieredCacheBackend) Delete(ctx context.Context, sha1 string) error {
+	return errors.Join(c.Front.Delete(ctx, sha1), c.Back.Delete(ctx, sha1))
+}
+
+// Cleanup - remove old verdicts from both backends
+func (c *TieredCacheBackend) Cleanup(ctx context.Context, date time.Time) error {
+	return errors.Join(c.Front.Cleanup(ctx, date), c.Back.Cleanup(ctx, date))
+}
+
+// Count - return amount of verdicts in back backend
+func (c *TieredCacheBackend) Count(ctx context.Context) (int, error) {
+	return c.Back.Count(ctx)
+}
+
+// Iterate - iterate entries of back backend
+func (c *TieredCacheBackend) Iterate(ctx context.Context, f func(entry *CacheEntry) error) error {
+	return c.Back.Iterate(ctx, f)
+}
+
+// Close - close both backends
+func (c *TieredCacheBackend) Close() error {
+	return errors.Join(c.Front.Close(), c.Back.Close())
+}
@@ -0,0 +1,128 @@
+package vone
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func testCacheResult(sha1 string) *SandboxAnalysisResultsResponseItem {
+	return &SandboxAnalysisResultsResponseItem{
+		Type: "file",
+		Digest: Digest{
+			MD5:    "md5" + sha1,
+			SHA1:   sha1,
+			SHA256: "sha256" + sha1,
+		},
+		AnalysisCompletionDateTime: VisionOneTime(time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)),
+		RiskLevel:                  RiskLevelHigh,
+		DetectionNames:             []string{"Trojan.Test"},
+		ThreatTypes:                []string{"Trojan"},
+		TrueFileType:               "PE-EXE",
+	}
+}
+
+func TestLRUCacheBackend(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	lru := NewLRUCacheBackend(2)
+	lru.now = func() time.Time { return now }
+	lru.Add(ctx, &CacheEntry{Result: testCacheResult("a")})
+	now = now.Add(time.Hour)
+	lru.Add(ctx, &CacheEntry{Result: testCacheResult("b")})
+	if entry, _ := lru.Query(ctx, "A"); entry == nil {
+		t.Fatal("a is not found")
+	}
+	now = now.Add(time.Hour)
+	lru.Add(ctx, &CacheEntry{Result: testCacheResult("c")})
+	if entry, _ := lru.Query(ctx, "b"); entry != nil {
+		t.Errorf("least recently used b is not evicted")
+	}
+	entry, _ := lru.Query(ctx, "a")
+	if entry == nil || !entry.Updated.Equal(now.Add(-2*time.Hour)) {
+		t.Fatalf("wrong a: %v", entry)
+	}
+	entry.Result.DetectionNames[0] = "changed"
+	if entry, _ := lru.Query(ctx, "a"); entry.Result.DetectionNames[0] != "Trojan.Test" {
+		t.Errorf("cached verdict is changed through returned one")
+	}
+	var order []string
+	lru.Iterate(ctx, func(entry *CacheEntry) error {
+		order = append(order, entry.Result.Digest.SHA1)
+		return nil
+	})
+	if len(order) != 2 || order[0] != "a" || order[1] != "c" {
+		t.Errorf("wrong order: %v", order)
+	}
+	lru.Cleanup(ctx, now.Add(-time.Minute))
+	if count, _ := lru.Count(ctx); count != 1 {
+		t.Errorf("expected 1 verdict after cleanup, got %d", count)
+	}
+	lru.Delete(ctx, "C")
+	if count, _ := lru.Count(ctx); count != 0 {
+		t.Errorf("expected no verdicts after delete, got %d", count)
+	}
+}
+
+func TestTieredCacheBackend(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "cache.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dialect := DetectSQLDialect(db); dialect != SQLDialectSQLite {
+		t.Errorf("expected SQLite dialect, got %v", dialect)
+	}
+	back, err := NewSQLCacheBackend(db, SQLDialectSQLite, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := back.Add(ctx, &CacheEntry{Result: testCacheResult("AAAA")}); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewCacheWithBackend(NewTieredCacheBackend(NewLRUCacheBackend(10), back))
+	defer cache.Close()
+	front := cache.Backend().(*TieredCacheBackend).Front
+	if count, _ := front.Count(ctx); count != 0 {
+		t.Fatalf("front is not empty")
+	}
+	data, updated, err := cache.Query(ctx, "aaaa")
+	if err != nil || data == nil || data.RiskLevel != RiskLevelHigh {
+		t.Fatalf("wrong verdict: %v, %v", data, err)
+	}
+	if cached, _ := front.Query(ctx, "aaaa"); cached == nil || !cached.Updated.Equal(updated) {
+		t.Errorf("verdict is not promoted to front: %v", cached)
+	}
+	if err := cache.Add(ctx, testCacheResult("BBBB")); err != nil {
+		t.Fatal(err)
+	}
+	for _, backend := range []CacheBackend{front, back} {
+		if count, _ := backend.Count(ctx); count != 2 {
+			t.Errorf("%T: expected 2 verdicts, got %d", backend, count)
+		}
+	}
+	if err := cache.DeleteContext(ctx, "bbbb"); err != nil {
+		t.Fatal(err)
+	}
+	for _, backend := range []CacheBackend{front, back} {
+		if entry, _ := backend.Query(ctx, "bbbb"); entry != nil {
+			t.Errorf("%T: verdict is not deleted", backend)
+		}
+	}
+}
+
+func TestNewSQLCacheBackendDialect(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "cache.sqlite3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := NewSQLCacheBackend(db, SQLDialect(10), "cache"); err == nil {
+		t.Errorf("unsupported dialect is accepted")
+	}
+}
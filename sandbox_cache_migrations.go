@@ -0,0 +1,171 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	sandbox_cache_migrations.go - versioned schema of SQL cache backend
+*/
+
+package vone
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var ErrCacheSchemaVersion = errors.New("cache schema version is newer than supported")
+
+// sqlMigration - step of cache schema evolution. Statements are run in one
+// transaction along with recording version in schema_version table
+type sqlMigration struct {
+	version    int
+	name       string
+	statements map[SQLDialect][]string
+}
+
+// forAllDialects - statements that are the same for all dialects
+func forAllDialects(statements ...string) map[SQLDialect][]string {
+	return map[SQLDialect][]string{
+		SQLDialectSQLite:     statements,
+		SQLDialectPostgreSQL: statements,
+	}
+}
+
+// sqlCacheMigrations - ordered cache schema migrations. Applied migrations
+// should never be changed - add new one instead
+var sqlCacheMigrations = []sqlMigration{
+	{
+		version: 1,
+		name:    "create hashes table",
+		// IF NOT EXISTS keeps caches created before schema versioning intact
+		statements: forAllDialects(
+			`CREATE TABLE IF NOT EXISTS hashes (
+		type TEXT NOT NULL,
+		md5 TEXT NOT NULL UNIQUE,
+		sha1 TEXT NOT NULL UNIQUE,
+		sha256 TEXT NOT NULL UNIQUE,
+		arguments TEXT,
+		AnalysisCompletionDateTime TEXT NOT NULL,
+		RiskLevel INTEGER,
+		DetectionNames TEXT,
+		ThreatTypes TEXT,
+		TrueFileType TEXT,
+		updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+		)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS hash_idx ON hashes (sha1)`,
+		),
+	},
+	{
+		version: 2,
+		name:    "add analysis ID, suspicious objects and artifacts",
+		statements: map[SQLDialect][]string{
+			SQLDialectSQLite: {
+				`ALTER TABLE hashes ADD COLUMN id TEXT NOT NULL DEFAULT ''`,
+				sqlSuspiciousObjectsTable,
+				`CREATE INDEX IF NOT EXISTS suspicious_objects_idx ON suspicious_objects (sha1)`,
+				fmt.Sprintf(sqlArtifactsTable, "BLOB"),
+			},
+			SQLDialectPostgreSQL: {
+				`ALTER TABLE hashes ADD COLUMN IF NOT EXISTS id TEXT NOT NULL DEFAULT ''`,
+				sqlSuspiciousObjectsTable,
+				`CREATE INDEX IF NOT EXISTS suspicious_objects_idx ON suspicious_objects (sha1)`,
+				fmt.Sprintf(sqlArtifactsTable, "BYTEA"),
+			},
+		},
+	},
+}
+
+// sqlSuspiciousObjectsTable - suspicious objects linked to hashes by SHA1 of analyzed file
+const sqlSuspiciousObjectsTable = `CREATE TABLE IF NOT EXISTS suspicious_objects (
+		sha1 TEXT NOT NULL,
+		RiskLevel INTEGER,
+		AnalysisCompletionDateTime TEXT NOT NULL,
+		ExpiredDateTime TEXT NOT NULL,
+		rootSha1 TEXT NOT NULL,
+		ip TEXT NOT NULL,
+		url TEXT NOT NULL,
+		fileSha1 TEXT NOT NULL,
+		domain TEXT NOT NULL
+		)`
+
+// sqlArtifactsTable - reports and investigation packages linked to hashes
+// by SHA1 of analyzed file. Format argument is binary data type
+const sqlArtifactsTable = `CREATE TABLE IF NOT EXISTS artifacts (
+		sha1 TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		path TEXT NOT NULL,
+		sha256 TEXT NOT NULL,
+		data %s,
+		PRIMARY KEY (sha1, kind)
+		)`
+
+// CacheSchemaVersion - schema version of SQL cache created by this package
+var CacheSchemaVersion = sqlCacheMigrations[len(sqlCacheMigrations)-1].version
+
+// SchemaVersion - return version of cache schema in database. Zero if
+// schema_version table is empty
+func (c *SQLCacheBackend) SchemaVersion(ctx context.Context) (int, error) {
+	version, err := schemaVersion(ctx, c.db)
+	return version, c.error("SchemaVersion", err)
+}
+
+// migrate - apply migrations with versions above current one in the order of versions
+func (c *SQLCacheBackend) migrate(ctx context.Context, migrations []sqlMigration) error {
+	stmt := `CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER NOT NULL PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+		)`
+	if _, err := c.db.ExecContext(ctx, stmt); err != nil {
+		return c.error("migrate", err)
+	}
+	version, err := schemaVersion(ctx, c.db)
+	if err != nil {
+		return c.error("migrate", err)
+	}
+	if latest := migrations[len(migrations)-1].version; version > latest {
+		return c.error("migrate", fmt.Errorf("%w: %d > %d", ErrCacheSchemaVersion, version, latest))
+	}
+	for _, migration := range migrations {
+		if migration.version <= version {
+			continue
+		}
+		if err := c.applyMigration(ctx, migration); err != nil {
+			// other process could apply the same migration simultaneously
+			if current, _ := schemaVersion(ctx, c.db); current >= migration.version {
+				continue
+			}
+			return c.error(fmt.Sprintf("migration %d (%s)", migration.version, migration.name), err)
+		}
+	}
+	return nil
+}
+
+// applyMigration - run migration statements and record its version in one transaction
+func (c *SQLCacheBackend) applyMigration(ctx context.Context, migration sqlMigration) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range migration.statements[c.dialect] {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	stmt := "INSERT INTO schema_version (version, name) VALUES ($1, $2)"
+	if _, err := tx.ExecContext(ctx, stmt, migration.version, migration.name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// schemaVersion - return the highest applied migration version
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version sql.NullInt64
+	if err := db.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
@@ -0,0 +1,106 @@
+package vone
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestSQLCacheBackendMigrate(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "cache.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// cache created before schema versioning
+	legacy := `CREATE TABLE hashes (
+		type TEXT NOT NULL,
+		md5 TEXT NOT NULL UNIQUE,
+		sha1 TEXT NOT NULL UNIQUE,
+		sha256 TEXT NOT NULL UNIQUE,
+		arguments TEXT,
+		AnalysisCompletionDateTime TEXT NOT NULL,
+		RiskLevel INTEGER,
+		DetectionNames TEXT,
+		ThreatTypes TEXT,
+		TrueFileType TEXT,
+		updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+		);
+		INSERT INTO hashes (type, md5, sha1, sha256, arguments, AnalysisCompletionDateTime,
+		RiskLevel, DetectionNames, ThreatTypes, TrueFileType)
+		VALUES ('file', 'MD5', 'SHA1', 'SHA256', '', '2026-01-10T00:00:00Z', 0, 'Trojan.Test', 'Trojan', 'PE-EXE')`
+	if _, err := db.Exec(legacy); err != nil {
+		t.Fatal(err)
+	}
+	backend, err := NewSQLCacheBackend(db, SQLDialectSQLite, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, err := backend.SchemaVersion(ctx); err != nil || version != CacheSchemaVersion {
+		t.Errorf("expected version %d, got %d, %v", CacheSchemaVersion, version, err)
+	}
+	if entry, err := backend.Query(ctx, "sha1"); err != nil || entry == nil {
+		t.Fatalf("legacy verdict is lost: %v", err)
+	}
+
+	next := CacheSchemaVersion + 1
+	migrations := append(slices.Clone(sqlCacheMigrations), sqlMigration{
+		version:    next,
+		name:       "add comment",
+		statements: forAllDialects(`ALTER TABLE hashes ADD COLUMN comment TEXT`),
+	})
+	for range 2 {
+		if err := backend.migrate(ctx, migrations); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if version, _ := backend.SchemaVersion(ctx); version != next {
+		t.Errorf("expected version %d, got %d", next, version)
+	}
+	var comment sql.NullString
+	if err := db.QueryRow("SELECT comment FROM hashes WHERE sha1='SHA1'").Scan(&comment); err != nil {
+		t.Errorf("column is not added: %v", err)
+	}
+
+	if _, err := NewSQLCacheBackend(db, SQLDialectSQLite, dbPath); !errors.Is(err, ErrCacheSchemaVersion) {
+		t.Errorf("expected ErrCacheSchemaVersion, got %v", err)
+	}
+}
+
+func TestSQLCacheBackendMigrateFailure(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "cache.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	backend, err := NewSQLCacheBackend(db, SQLDialectSQLite, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations := append(slices.Clone(sqlCacheMigrations), sqlMigration{
+		version: CacheSchemaVersion + 1,
+		name:    "broken",
+		statements: forAllDialects(
+			`CREATE TABLE partial (id INTEGER)`,
+			`ALTER TABLE missing ADD COLUMN comment TEXT`,
+		),
+	})
+	if err := backend.migrate(ctx, migrations); err == nil {
+		t.Fatal("broken migration succeeded")
+	}
+	if version, _ := backend.SchemaVersion(ctx); version != CacheSchemaVersion {
+		t.Errorf("version of failed migration is recorded: %d", version)
+	}
+	if _, err := db.Exec("SELECT id FROM partial"); err == nil {
+		t.Errorf("failed migration is not rolled back")
+	}
+}
@@ -0,0 +1,210 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	sandbox_cache_query.go - select cached verdicts by criteria
+*/
+
+package vone
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CacheOrder - field to sort selected cache entries by
+type CacheOrder int
+
+const (
+	CacheOrderSHA1     CacheOrder = iota // SHA1 of file
+	CacheOrderUpdated                    // Time entry was added to cache
+	CacheOrderAnalyzed                   // Analysis completion time
+	CacheOrderRisk                       // Risk level from the highest to no risk
+)
+
+// CacheQuery - criteria to select cached entries. Entries match all criteria
+// set. Usage:
+//
+//	query := vone.NewCacheQuery().
+//		RiskLevelAtLeast(vone.RiskLevelHigh).
+//		TrueFileType("PE-EXE").
+//		UpdatedBetween(time.Now().Add(-7*24*time.Hour), time.Time{})
+//	for entry, err := range cache.Select(ctx, query) {
+//	...
+type CacheQuery struct {
+	hash          string
+	riskLevel     RiskLevel
+	riskLevelSet  bool
+	detectionName string
+	trueFileType  string
+	analyzedFrom  time.Time
+	analyzedTo    time.Time
+	updatedFrom   time.Time
+	updatedTo     time.Time
+	order         CacheOrder
+	descending    bool
+	offset        int
+	limit         int
+}
+
+// NewCacheQuery - create query matching all entries ordered by SHA1
+func NewCacheQuery() *CacheQuery {
+	return &CacheQuery{}
+}
+
+// Hash - select entry with given MD5, SHA1 or SHA256
+func (q *CacheQuery) Hash(hash string) *CacheQuery {
+	q.hash = strings.ToUpper(hash)
+	return q
+}
+
+// RiskLevelAtLeast - select entries with given or higher risk level
+func (q *CacheQuery) RiskLevelAtLeast(riskLevel RiskLevel) *CacheQuery {
+	q.riskLevel = riskLevel
+	q.riskLevelSet = true
+	return q
+}
+
+// DetectionName - select entries with detection name containing given
+// substring (case insensitive)
+func (q *CacheQuery) DetectionName(substring string) *CacheQuery {
+	q.detectionName = strings.ToLower(substring)
+	return q
+}
+
+// TrueFileType - select entries of given file type (case insensitive)
+func (q *CacheQuery) TrueFileType(trueFileType string) *CacheQuery {
+	q.trueFileType = strings.ToLower(trueFileType)
+	return q
+}
+
+// AnalyzedBetween - select entries with analysis completed not before from and
+// before to. Zero time means no limit
+func (q *CacheQuery) AnalyzedBetween(from, to time.Time) *CacheQuery {
+	q.analyzedFrom, q.analyzedTo = from, to
+	return q
+}
+
+// UpdatedBetween - select entries added to cache not before from and before
+// to. Zero time means no limit
+func (q *CacheQuery) UpdatedBetween(from, to time.Time) *CacheQuery {
+	q.updatedFrom, q.updatedTo = from, to
+	return q
+}
+
+// OrderBy - sort entries by given field. Entries with equal fields are sorted by SHA1
+func (q *CacheQuery) OrderBy(order CacheOrder, descending bool) *CacheQuery {
+	q.order, q.descending = order, descending
+	return q
+}
+
+// Page - skip offset entries and return up to limit entries. Zero limit means no limit
+func (q *CacheQuery) Page(offset, limit int) *CacheQuery {
+	q.offset, q.limit = offset, limit
+	return q
+}
+
+// Match - check whether entry matches query criteria. Order and paging are ignored
+func (q *CacheQuery) Match(entry *CacheEntry) bool {
+	data := entry.Result
+	if q.hash != "" && !slices.ContainsFunc([]string{data.Digest.MD5, data.Digest.SHA1, data.Digest.SHA256}, func(hash string) bool {
+		return strings.EqualFold(hash, q.hash)
+	}) {
+		return false
+	}
+	// lower value means higher risk
+	if q.riskLevelSet && data.RiskLevel > q.riskLevel {
+		return false
+	}
+	if q.detectionName != "" && !slices.ContainsFunc(data.DetectionNames, func(name string) bool {
+		return strings.Contains(strings.ToLower(name), q.detectionName)
+	}) {
+		return false
+	}
+	if q.trueFileType != "" && !strings.EqualFold(data.TrueFileType, q.trueFileType) {
+		return false
+	}
+	return inTimeRange(time.Time(data.AnalysisCompletionDateTime), q.analyzedFrom, q.analyzedTo) &&
+		inTimeRange(entry.Updated, q.updatedFrom, q.updatedTo)
+}
+
+// inTimeRange - check whether t is in [from, to) range. Zero bounds mean no limit
+func inTimeRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+// compare - compare entries according to query order
+func (q *CacheQuery) compare(a, b *CacheEntry) int {
+	var result int
+	switch q.order {
+	case CacheOrderUpdated:
+		result = a.Updated.Compare(b.Updated)
+	case CacheOrderAnalyzed:
+		result = time.Time(a.Result.AnalysisCompletionDateTime).Compare(time.Time(b.Result.AnalysisCompletionDateTime))
+	case CacheOrderRisk:
+		result = cmp.Compare(a.Result.RiskLevel, b.Result.RiskLevel)
+	}
+	if result == 0 {
+		result = cmp.Compare(strings.ToUpper(a.Result.Digest.SHA1), strings.ToUpper(b.Result.Digest.SHA1))
+	}
+	if q.descending {
+		return -result
+	}
+	return result
+}
+
+// CacheSelector - backend able to select entries by query itself (like
+// SQLCacheBackend). Other backends are filtered by iterating all entries
+type CacheSelector interface {
+	Select(ctx context.Context, query *CacheQuery) iter.Seq2[*CacheEntry, error]
+}
+
+var (
+	_ CacheSelector = &SQLCacheBackend{}
+	_ CacheSelector = &TieredCacheBackend{}
+)
+
+// Select - return entries matching query. Iteration stops after the first error
+func (c *Cache) Select(ctx context.Context, query *CacheQuery) iter.Seq2[*CacheEntry, error] {
+	return selectEntries(ctx, c.backend, query)
+}
+
+// Select - select entries of back backend
+func (c *TieredCacheBackend) Select(ctx context.Context, query *CacheQuery) iter.Seq2[*CacheEntry, error] {
+	return selectEntries(ctx, c.Back, query)
+}
+
+// selectEntries - select entries by backend itself if it supports queries or
+// filter, sort and page all of its entries in memory
+func selectEntries(ctx context.Context, backend CacheBackend, query *CacheQuery) iter.Seq2[*CacheEntry, error] {
+	if selector, ok := backend.(CacheSelector); ok {
+		return selector.Select(ctx, query)
+	}
+	return func(yield func(*CacheEntry, error) bool) {
+		var entries []*CacheEntry
+		err := backend.Iterate(ctx, func(entry *CacheEntry) error {
+			if query.Match(entry) {
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		slices.SortFunc(entries, query.compare)
+		entries = entries[min(query.offset, len(entries)):]
+		if query.limit > 0 {
+			entries = entries[:min(query.limit, len(entries))]
+		}
+		for _, entry := range entries {
+			if !yield(entry, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package vone
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestCacheSelect(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "cache.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlBackend, err := NewSQLCacheBackend(db, SQLDialectSQLite, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caches := map[string]*Cache{
+		"sql":  NewCacheWithBackend(sqlBackend),
+		"lru":  NewCacheWithBackend(NewLRUCacheBackend(0)),
+		"bolt": NewCacheWithBackend(newTestBoltCacheBackend(t)),
+	}
+	for _, cache := range caches {
+		defer cache.Close()
+	}
+	week := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	entries := []struct {
+		sha1         string
+		riskLevel    RiskLevel
+		detections   []string
+		trueFileType string
+		analyzed     time.Time
+	}{
+		{"A1", RiskLevelHigh, []string{"Trojan.Win32.Test", "Worm.Generic"}, "PE-EXE", week.Add(time.Hour)},
+		{"B2", RiskLevelHigh, []string{"Ransom.Win32.Test"}, "PE-DLL", week.Add(2 * time.Hour)},
+		{"C3", RiskLevelMedium, []string{"TROJAN_Generic"}, "PE-EXE", week.Add(-time.Hour)},
+		{"D4", RiskLevelLow, []string{"PUA.Test_100%"}, "ZIP", week.Add(3 * time.Hour)},
+		{"E5", RiskLevelNoRisk, []string{""}, "PE-EXE", week.Add(4 * time.Hour)},
+	}
+	for _, e := range entries {
+		result := testCacheResult(e.sha1)
+		result.RiskLevel = e.riskLevel
+		result.DetectionNames = e.detections
+		result.TrueFileType = e.trueFileType
+		result.AnalysisCompletionDateTime = VisionOneTime(e.analyzed)
+		for _, cache := range caches {
+			if err := cache.Add(ctx, result); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	now := time.Now()
+	testCases := []struct {
+		query    *CacheQuery
+		expected string
+	}{
+		{NewCacheQuery(), "[A1 B2 C3 D4 E5]"},
+		{NewCacheQuery().Hash("md5c3"), "[C3]"},
+		{NewCacheQuery().Hash("sha256d4"), "[D4]"},
+		{NewCacheQuery().Hash("b2"), "[B2]"},
+		{NewCacheQuery().RiskLevelAtLeast(RiskLevelMedium), "[A1 B2 C3]"},
+		{NewCacheQuery().DetectionName("trojan"), "[A1 C3]"},
+		{NewCacheQuery().DetectionName("_100%"), "[D4]"},
+		{NewCacheQuery().DetectionName("m_w"), "[]"},
+		{NewCacheQuery().DetectionName("worm"), "[A1]"},
+		{NewCacheQuery().DetectionName("test,worm"), "[]"},
+		{NewCacheQuery().DetectionName("generic"), "[A1 C3]"},
+		{NewCacheQuery().TrueFileType("pe-exe"), "[A1 C3 E5]"},
+		{NewCacheQuery().AnalyzedBetween(week, week.Add(3*time.Hour)), "[A1 B2]"},
+		{NewCacheQuery().AnalyzedBetween(week.Add(3*time.Hour), time.Time{}), "[D4 E5]"},
+		{NewCacheQuery().UpdatedBetween(now.Add(-time.Hour), now.Add(time.Hour)), "[A1 B2 C3 D4 E5]"},
+		{NewCacheQuery().UpdatedBetween(now.Add(time.Hour), time.Time{}), "[]"},
+		{NewCacheQuery().RiskLevelAtLeast(RiskLevelHigh).TrueFileType("PE-EXE").AnalyzedBetween(week, week.Add(7*24*time.Hour)), "[A1]"},
+		{NewCacheQuery().OrderBy(CacheOrderAnalyzed, true), "[E5 D4 B2 A1 C3]"},
+		{NewCacheQuery().OrderBy(CacheOrderRisk, false), "[A1 B2 C3 D4 E5]"},
+		{NewCacheQuery().OrderBy(CacheOrderRisk, true), "[E5 D4 C3 B2 A1]"},
+		{NewCacheQuery().Page(1, 2), "[B2 C3]"},
+		{NewCacheQuery().Page(3, 0), "[D4 E5]"},
+		{NewCacheQuery().Page(10, 0), "[]"},
+	}
+	for name, cache := range caches {
+		for i, tc := range testCases {
+			var actual []string
+			for entry, err := range cache.Select(ctx, tc.query) {
+				if err != nil {
+					t.Fatalf("%s %d: %v", name, i, err)
+				}
+				actual = append(actual, entry.Result.Digest.SHA1)
+			}
+			if fmt.Sprint(actual) != tc.expected {
+				t.Errorf("%s %d: expected %s, got %v", name, i, tc.expected, actual)
+			}
+		}
+	}
+	for entry, err := range caches["sql"].Select(ctx, NewCacheQuery()) {
+		if err != nil || entry.Result.Digest.SHA1 != "A1" {
+			t.Errorf("unexpected first entry: %v, %v", entry, err)
+		}
+		break
+	}
+}
@@ -0,0 +1,523 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	sandbox_cache_sql.go - cache backend keeping verdicts in SQL database
+*/
+
+package vone
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SQLDialect - SQL database flavor used by SQLCacheBackend
+type SQLDialect int
+
+const (
+	SQLDialectSQLite SQLDialect = iota
+	SQLDialectPostgreSQL
+)
+
+// String - return name of dialect
+func (d SQLDialect) String() string {
+	switch d {
+	case SQLDialectSQLite:
+		return "SQLite"
+	case SQLDialectPostgreSQL:
+		return "PostgreSQL"
+	default:
+		return fmt.Sprintf("SQLDialect(%d)", int(d))
+	}
+}
+
+// DetectSQLDialect - guess dialect by database driver type. Drivers of
+// lib/pq and pgx packages mean PostgreSQL, anything else - SQLite
+func DetectSQLDialect(db *sql.DB) SQLDialect {
+	driver := fmt.Sprintf("%T", db.Driver())
+	for _, name := range []string{"pq.", "pgx", "stdlib."} {
+		if strings.Contains(driver, name) {
+			return SQLDialectPostgreSQL
+		}
+	}
+	return SQLDialectSQLite
+}
+
+// SQLCacheBackend - cache backend keeping verdicts in hashes table of SQLite
+// or PostgreSQL database
+type SQLCacheBackend struct {
+	dialect SQLDialect
+	dbPath  string
+	db      *sql.DB
+}
+
+// sqlCacheColumns - columns of hashes table in the order of Scan
+const sqlCacheColumns = `type,
+		md5,
+		sha1,
+		sha256,
+		arguments,
+		AnalysisCompletionDateTime,
+		RiskLevel,
+		DetectionNames,
+		ThreatTypes,
+		TrueFileType,
+		id`
+
+// sqlSuspiciousObjectColumns - columns of suspicious_objects table in the order of Scan
+const sqlSuspiciousObjectColumns = `sha1,
+		RiskLevel,
+		AnalysisCompletionDateTime,
+		ExpiredDateTime,
+		rootSha1,
+		ip,
+		url,
+		fileSha1,
+		domain`
+
+// Kinds of artifacts
+const (
+	sqlArtifactReport               = "report"
+	sqlArtifactInvestigationPackage = "investigationPackage"
+)
+
+// NewSQLCacheBackend - create or upgrade cache schema and return backend
+// using it. dbPath is used in error messages only
+func NewSQLCacheBackend(db *sql.DB, dialect SQLDialect, dbPath string) (*SQLCacheBackend, error) {
+	if dialect != SQLDialectSQLite && dialect != SQLDialectPostgreSQL {
+		return nil, fmt.Errorf("%s: unsupported SQL dialect: %v", dbPath, dialect)
+	}
+	c := &SQLCacheBackend{
+		dialect: dialect,
+		dbPath:  dbPath,
+		db:      db,
+	}
+	if err := c.migrate(context.Background(), sqlCacheMigrations); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Dialect - return SQL dialect of backend
+func (c *SQLCacheBackend) Dialect() SQLDialect {
+	return c.dialect
+}
+
+// Add - add Analyzer check result with its suspicious objects and artifacts to cache database
+func (c *SQLCacheBackend) Add(ctx context.Context, entry *CacheEntry) error {
+	data := entry.Result
+	sha1 := strings.ToUpper(data.Digest.SHA1)
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return c.error("Add Begin", err)
+	}
+	defer tx.Rollback()
+	stmt := `INSERT OR REPLACE INTO hashes (` + sqlCacheColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	if c.dialect == SQLDialectPostgreSQL {
+		stmt = `INSERT INTO hashes (` + sqlCacheColumns + `
+		) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ON CONFLICT (sha1) DO UPDATE SET
+		arguments=$5,
+		AnalysisCompletionDateTime=$6,
+		RiskLevel=$7,
+		DetectionNames=$8,
+		ThreatTypes=$9,
+		TrueFileType=$10,
+		id=$11,
+		updated=CURRENT_TIMESTAMP`
+	}
+	_, err = tx.ExecContext(ctx, stmt,
+		data.Type,
+		strings.ToUpper(data.Digest.MD5),
+		sha1,
+		strings.ToUpper(data.Digest.SHA256),
+		data.Arguments,
+		data.AnalysisCompletionDateTime.String(),
+		data.RiskLevel,
+		strings.Join(data.DetectionNames, ","),
+		strings.Join(data.ThreatTypes, ","),
+		data.TrueFileType,
+		data.ID)
+	if err != nil {
+		return c.error("Add Exec", err)
+	}
+	if err := deleteCacheChildren(ctx, tx, sha1); err != nil {
+		return c.error("Add Exec", err)
+	}
+	stmt = `INSERT INTO suspicious_objects (` + sqlSuspiciousObjectColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	for _, so := range entry.SuspiciousObjects {
+		_, err := tx.ExecContext(ctx, stmt,
+			sha1,
+			so.RiskLevel,
+			so.AnalysisCompletionDateTime.String(),
+			so.ExpiredDateTime.String(),
+			so.RootSHA1,
+			so.IP,
+			so.URL,
+			so.FileSHA1,
+			so.Domain)
+		if err != nil {
+			return c.error("Add suspicious object", err)
+		}
+	}
+	stmt = "INSERT INTO artifacts (sha1, kind, path, sha256, data) VALUES ($1, $2, $3, $4, $5)"
+	for kind, artifact := range map[string]*CacheArtifact{
+		sqlArtifactReport:               entry.Report,
+		sqlArtifactInvestigationPackage: entry.InvestigationPackage,
+	} {
+		if artifact == nil {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, stmt, sha1, kind, artifact.Path, artifact.SHA256, artifact.Data); err != nil {
+			return c.error("Add artifact", err)
+		}
+	}
+	return c.error("Add Commit", tx.Commit())
+}
+
+// deleteCacheChildren - delete suspicious objects and artifacts of SHA1
+func deleteCacheChildren(ctx context.Context, tx *sql.Tx, sha1 string) error {
+	for _, stmt := range []string{
+		"DELETE FROM suspicious_objects WHERE sha1=$1",
+		"DELETE FROM artifacts WHERE sha1=$1",
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, sha1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete - delete entity from hashes table along with its suspicious objects and artifacts
+func (c *SQLCacheBackend) Delete(ctx context.Context, sha1 string) error {
+	sha1 = strings.ToUpper(sha1)
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return c.error("Delete Begin", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM hashes where sha1=$1", sha1); err != nil {
+		return c.error("Delete Exec", err)
+	}
+	if err := deleteCacheChildren(ctx, tx, sha1); err != nil {
+		return c.error("Delete Exec", err)
+	}
+	return c.error("Delete Commit", tx.Commit())
+}
+
+// Cleanup - remove data from cache that was put there before
+// time provided
+func (c *SQLCacheBackend) Cleanup(ctx context.Context, date time.Time) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return c.error("Cleanup Begin", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM hashes where updated < $1", c.timeParam(date)); err != nil {
+		return c.error("Cleanup hashes", err)
+	}
+	// also removes rows left by verdicts replaced with other SHA1 and the same MD5 or SHA256
+	for _, table := range []string{"suspicious_objects", "artifacts"} {
+		stmt := "DELETE FROM " + table + " WHERE sha1 NOT IN (SELECT sha1 FROM hashes)"
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return c.error("Cleanup "+table, err)
+		}
+	}
+	return c.error("Cleanup Commit", tx.Commit())
+}
+
+// Query - get cached Analyzer check result with its suspicious objects and artifacts for SHA1 of file
+func (c *SQLCacheBackend) Query(ctx context.Context, sha1 string) (*CacheEntry, error) {
+	stmt := `SELECT ` + sqlCacheColumns + `,
+		updated FROM hashes WHERE sha1=$1`
+	rows, err := c.db.QueryContext(ctx, stmt, strings.ToUpper(sha1))
+	if err != nil {
+		return nil, c.error("Query", err)
+	}
+	defer rows.Close()
+	if rows.Err() != nil {
+		return nil, c.error("Query", rows.Err())
+	}
+	if !rows.Next() {
+		return nil, nil
+	}
+	data, updated, err := c.ScanSandboxAnalysisResultsResponse(rows)
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+	return c.entry(ctx, data, updated)
+}
+
+// entry - load suspicious objects and artifacts of verdict
+func (c *SQLCacheBackend) entry(ctx context.Context, data *SandboxAnalysisResultsResponseItem, updated time.Time) (*CacheEntry, error) {
+	entry := &CacheEntry{
+		Result:  data,
+		Updated: updated,
+	}
+	sha1 := strings.ToUpper(data.Digest.SHA1)
+	stmt := `SELECT ` + sqlSuspiciousObjectColumns + ` FROM suspicious_objects WHERE sha1=$1`
+	rows, err := c.db.QueryContext(ctx, stmt, sha1)
+	if err != nil {
+		return nil, c.error("Query suspicious objects", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var so SandboxSuspiciousObject
+		var sha1, analysisCompletionDateTime, expiredDateTime string
+		err := rows.Scan(&sha1, &so.RiskLevel, &analysisCompletionDateTime, &expiredDateTime,
+			&so.RootSHA1, &so.IP, &so.URL, &so.FileSHA1, &so.Domain)
+		if err != nil {
+			return nil, c.error("Query suspicious objects row.Scan", err)
+		}
+		if so.AnalysisCompletionDateTime, err = parseVisionOneTime(analysisCompletionDateTime); err != nil {
+			return nil, c.error("Query suspicious objects", err)
+		}
+		if so.ExpiredDateTime, err = parseVisionOneTime(expiredDateTime); err != nil {
+			return nil, c.error("Query suspicious objects", err)
+		}
+		entry.SuspiciousObjects = append(entry.SuspiciousObjects, so)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, c.error("Query suspicious objects", err)
+	}
+	rows.Close()
+	stmt = "SELECT kind, path, sha256, data FROM artifacts WHERE sha1=$1"
+	rows, err = c.db.QueryContext(ctx, stmt, sha1)
+	if err != nil {
+		return nil, c.error("Query artifacts", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var kind string
+		artifact := &CacheArtifact{}
+		if err := rows.Scan(&kind, &artifact.Path, &artifact.SHA256, &artifact.Data); err != nil {
+			return nil, c.error("Query artifacts row.Scan", err)
+		}
+		switch kind {
+		case sqlArtifactReport:
+			entry.Report = artifact
+		case sqlArtifactInvestigationPackage:
+			entry.InvestigationPackage = artifact
+		}
+	}
+	return entry, c.error("Query artifacts", rows.Err())
+}
+
+// ScanSandboxAnalysisResultsResponse - read verdict from rows selected with
+// sqlCacheColumns. Rows selected without id column, as before it was added, are
+// supported too
+func (c *SQLCacheBackend) ScanSandboxAnalysisResultsResponse(rows *sql.Rows) (*SandboxAnalysisResultsResponseItem, time.Time, error) {
+	var data SandboxAnalysisResultsResponseItem
+	var detectionNames, threatTypes string
+	var analysisCompletionDateTime string
+	var updated string
+	dest := []any{&data.Type, &data.Digest.MD5, &data.Digest.SHA1, &data.Digest.SHA256,
+		&data.Arguments, &analysisCompletionDateTime, &data.RiskLevel, &detectionNames,
+		&threatTypes, &data.TrueFileType, &data.ID, &updated}
+	if columns, err := rows.Columns(); err == nil && len(columns) == len(dest)-1 {
+		dest = slices.Delete(dest, len(dest)-2, len(dest)-1)
+	}
+	err := rows.Scan(dest...)
+	if err != nil {
+		return nil, time.Time{}, c.error("Query row.Scan", err)
+	}
+	data.AnalysisCompletionDateTime, err = parseVisionOneTime(analysisCompletionDateTime)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("ScanSandboxAnalysisResults Parse \"%s\": %w",
+			analysisCompletionDateTime, err)
+	}
+
+	data.DetectionNames = strings.Split(detectionNames, ",")
+	data.ThreatTypes = strings.Split(threatTypes, ",")
+	date, err := time.Parse(timeFormatZ, updated)
+	if err != nil {
+		return nil, time.Time{}, c.error("IterateCache time.Parse \""+updated+"\"", err)
+	}
+	return &data, date, nil
+}
+
+// Select - select entries matching query by SQL statement
+func (c *SQLCacheBackend) Select(ctx context.Context, query *CacheQuery) iter.Seq2[*CacheEntry, error] {
+	return func(yield func(*CacheEntry, error) bool) {
+		stmt, args := c.selectStatement(query)
+		rows, err := c.db.QueryContext(ctx, stmt, args...)
+		if err != nil {
+			yield(nil, c.error("Select", err))
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			data, updated, err := c.ScanSandboxAnalysisResultsResponse(rows)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			entry, err := c.entry(ctx, data, updated)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(entry, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, c.error("Select", err))
+		}
+	}
+}
+
+// selectStatement - return SELECT statement for query and its arguments
+func (c *SQLCacheBackend) selectStatement(query *CacheQuery) (string, []any) {
+	var conditions []string
+	var args []any
+	// add - add condition replacing each ? with placeholder of next argument
+	add := func(condition string, values ...any) {
+		for _, value := range values {
+			args = append(args, value)
+			condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(args)), 1)
+		}
+		conditions = append(conditions, condition)
+	}
+	if query.hash != "" {
+		add("(md5=? OR sha1=? OR sha256=?)", query.hash, query.hash, query.hash)
+	}
+	if query.riskLevelSet {
+		// lower value means higher risk
+		add("RiskLevel <= ?", query.riskLevel)
+	}
+	if query.detectionName != "" {
+		// names are joined by comma, so substring with comma would match
+		// across neighbouring names
+		if strings.Contains(query.detectionName, ",") {
+			add("1=0")
+		} else {
+			add(`LOWER(DetectionNames) LIKE ? ESCAPE '\'`, "%"+escapeLike(query.detectionName)+"%")
+		}
+	}
+	if query.trueFileType != "" {
+		add("LOWER(TrueFileType) = ?", query.trueFileType)
+	}
+	if !query.analyzedFrom.IsZero() {
+		add("AnalysisCompletionDateTime >= ?", VisionOneTime(query.analyzedFrom.UTC()).String())
+	}
+	if !query.analyzedTo.IsZero() {
+		add("AnalysisCompletionDateTime < ?", VisionOneTime(query.analyzedTo.UTC()).String())
+	}
+	if !query.updatedFrom.IsZero() {
+		add("updated >= ?", c.timeParam(query.updatedFrom))
+	}
+	if !query.updatedTo.IsZero() {
+		add("updated < ?", c.timeParam(query.updatedTo))
+	}
+	stmt := `SELECT ` + sqlCacheColumns + `,
+		updated FROM hashes`
+	if len(conditions) > 0 {
+		stmt += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	column := map[CacheOrder]string{
+		CacheOrderSHA1:     "sha1",
+		CacheOrderUpdated:  "updated",
+		CacheOrderAnalyzed: "AnalysisCompletionDateTime",
+		CacheOrderRisk:     "RiskLevel",
+	}[query.order]
+	if column == "" {
+		column = "sha1"
+	}
+	direction := " ASC"
+	if query.descending {
+		direction = " DESC"
+	}
+	stmt += " ORDER BY " + column + direction + ", sha1" + direction
+	if query.limit > 0 {
+		args = append(args, query.limit)
+		stmt += fmt.Sprintf(" LIMIT $%d", len(args))
+	} else if query.offset > 0 && c.dialect == SQLDialectSQLite {
+		// SQLite does not support OFFSET without LIMIT
+		stmt += " LIMIT -1"
+	}
+	if query.offset > 0 {
+		args = append(args, query.offset)
+		stmt += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	return stmt, args
+}
+
+// timeParam - time as it is compared with updated column. SQLite keeps
+// CURRENT_TIMESTAMP as text in UTC
+func (c *SQLCacheBackend) timeParam(t time.Time) any {
+	if c.dialect == SQLDialectSQLite {
+		return t.UTC().Format(time.DateTime)
+	}
+	return t
+}
+
+// escapeLike - escape LIKE pattern special characters with backslash
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// parseVisionOneTime - parse time stored by VisionOneTime.String
+func parseVisionOneTime(value string) (VisionOneTime, error) {
+	t, err := time.Parse(timeFormat, value)
+	if err != nil {
+		t, err = time.Parse(timeFormatZ, value)
+	}
+	return VisionOneTime(t), err
+}
+
+// Count - return number of entities in cache database
+func (c *SQLCacheBackend) Count(ctx context.Context) (int, error) {
+	var value int
+	if err := c.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM hashes").Scan(&value); err != nil {
+		return -1, c.error("Count", err)
+	}
+	return value, nil
+}
+
+// Close database - should be called when database is not in use anymore
+func (c *SQLCacheBackend) Close() error {
+	return c.db.Close()
+}
+
+// Iterate - perform provided function fo each database entity
+func (c *SQLCacheBackend) Iterate(ctx context.Context, f func(entry *CacheEntry) error) error {
+	stmt := `SELECT ` + sqlCacheColumns + `,
+		updated
+		FROM hashes`
+	rows, err := c.db.QueryContext(ctx, stmt)
+	if err != nil {
+		return c.error("IterateCache Query", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		data, updated, err := c.ScanSandboxAnalysisResultsResponse(rows)
+		if err != nil {
+			return c.error("IterateCache", err)
+		}
+		entry, err := c.entry(ctx, data, updated)
+		if err != nil {
+			return err
+		}
+		err = f(entry)
+		if err != nil {
+			return c.error("IterateCache callback", err)
+		}
+	}
+	return c.error("IterateCache Query", rows.Err())
+}
+
+func (c *SQLCacheBackend) error(message string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %s: %w", c.dbPath, message, err)
+}
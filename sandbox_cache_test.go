@@ -3,6 +3,7 @@ package vone
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -246,3 +247,127 @@ func TestAddAndIterate(t *testing.T) {
 		t.Errorf("Expected count %d, but got %d", expected, actual)
 	}
 }
+
+func TestAddEntryAndQuery(t *testing.T) {
+	ctx := context.Background()
+	folder := t.TempDir()
+	dbPath := filepath.Join(folder, "cache.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewCache(db, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	reportPath := filepath.Join(folder, "report.pdf")
+	if err := os.WriteFile(reportPath, []byte("%PDF-1.4"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	report, err := NewCacheArtifact(reportPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := VisionOneTime(time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC))
+	result := testCacheResult("efgh")
+	result.ID = "00000000-0000-0000-0000-000000000001"
+	entry := &CacheEntry{
+		Result: result,
+		SuspiciousObjects: []SandboxSuspiciousObject{
+			{RiskLevel: RiskLevelHigh, Domain: "example.com", ExpiredDateTime: expired},
+			{RiskLevel: RiskLevelMedium, FileSHA1: "ABCD", RootSHA1: "EFGH"},
+		},
+		Report:               report,
+		InvestigationPackage: &CacheArtifact{Path: filepath.Join(folder, "package.zip"), SHA256: "00"},
+	}
+	if err := cache.AddEntry(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := cache.QueryEntry(ctx, "EFGH")
+	if err != nil || actual == nil {
+		t.Fatalf("entry is not found: %v", err)
+	}
+	if actual.Result.ID != result.ID {
+		t.Errorf("expected ID %s, got %s", result.ID, actual.Result.ID)
+	}
+	if len(actual.SuspiciousObjects) != 2 || actual.SuspiciousObjects[0].Domain != "example.com" ||
+		!time.Time(actual.SuspiciousObjects[0].ExpiredDateTime).Equal(time.Time(expired)) ||
+		actual.SuspiciousObjects[1].RootSHA1 != "EFGH" {
+		t.Errorf("wrong suspicious objects: %+v", actual.SuspiciousObjects)
+	}
+	if actual.Report == nil || string(actual.Report.Data) != "%PDF-1.4" || actual.Report.SHA256 != report.SHA256 {
+		t.Errorf("wrong report: %+v", actual.Report)
+	}
+	if actual.InvestigationPackage == nil || actual.InvestigationPackage.Data != nil {
+		t.Errorf("wrong investigation package: %+v", actual.InvestigationPackage)
+	}
+
+	if err := os.Remove(reportPath); err != nil {
+		t.Fatal(err)
+	}
+	restoredPath := filepath.Join(folder, "restored.pdf")
+	if path, err := actual.Report.Restore(restoredPath); err != nil || path != restoredPath {
+		t.Errorf("report is not restored: %s, %v", path, err)
+	}
+	if _, err := actual.InvestigationPackage.Restore(filepath.Join(folder, "restored.zip")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := cache.Add(ctx, result); err != nil {
+		t.Fatal(err)
+	}
+	if actual, _ := cache.QueryEntry(ctx, "efgh"); len(actual.SuspiciousObjects) != 0 || actual.Report != nil {
+		t.Errorf("replaced entry keeps suspicious objects or artifacts: %+v", actual)
+	}
+	if err := cache.AddEntry(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Delete("efgh"); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range []string{"hashes", "suspicious_objects", "artifacts"} {
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil || count != 0 {
+			t.Errorf("%s: expected no rows, got %d, %v", table, count, err)
+		}
+	}
+}
+
+func TestScanSandboxAnalysisResultsResponse(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "cache.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewCache(db, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	if err := cache.Add(ctx, testCacheResult("ABCD")); err != nil {
+		t.Fatal(err)
+	}
+	// columns selected before analysis ID was cached
+	rows, err := db.Query(`SELECT type, md5, sha1, sha256, arguments, AnalysisCompletionDateTime,
+		RiskLevel, DetectionNames, ThreatTypes, TrueFileType, updated FROM hashes`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("no rows")
+	}
+	data, updated, err := cache.ScanSandboxAnalysisResultsResponse(rows)
+	if err != nil || data.Digest.SHA1 != "ABCD" || updated.IsZero() {
+		t.Errorf("wrong verdict: %v, %v, %v", data, updated, err)
+	}
+	rows.Close()
+	if err := cache.Delete("abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := cache.Count(ctx); count != 0 {
+		t.Errorf("verdict is not deleted")
+	}
+}
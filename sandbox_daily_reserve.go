@@ -53,6 +53,10 @@ func (s *sandboxDailyReserveRequest) url() string {
 	return "/v3.0/sandbox/submissionUsage"
 }
 
+func (s *sandboxDailyReserveRequest) operation() string {
+	return "SandboxDailyReserve"
+}
+
 func (f *sandboxDailyReserveRequest) responseStruct() any {
 	return &f.response
 }
@@ -22,12 +22,16 @@ type sandboxDownloadResultsRequest struct {
 	baseRequest
 	id       string
 	response io.ReadCloser
+	// request - request to be sent. Differs from download results request itself
+	// when it is embedded into other request
+	request vOneRequest
 }
 
 var _ vOneRequest = &sandboxDownloadResultsRequest{}
 
 func (v *VOne) SandboxDownloadResults(id string) *sandboxDownloadResultsRequest {
 	f := &sandboxDownloadResultsRequest{id: id}
+	f.request = f
 	f.baseRequest.init(v)
 	return f
 }
@@ -41,7 +45,7 @@ func (f *sandboxDownloadResultsRequest) Do(ctx context.Context) (io.ReadCloser,
 	if err := f.checkUsed(); err != nil {
 		return nil, fmt.Errorf("download result/investigation package: %w", err)
 	}
-	if err := f.vone.call(ctx, f); err != nil {
+	if err := f.vone.call(ctx, f.request); err != nil {
 		return nil, err
 	}
 	return f.response, nil
@@ -69,6 +73,10 @@ func (f *sandboxDownloadResultsRequest) url() string {
 	return fmt.Sprintf("/v3.0/sandbox/analysisResults/%s/report", f.id)
 }
 
+func (f *sandboxDownloadResultsRequest) operation() string {
+	return "SandboxDownloadResults"
+}
+
 func (f *sandboxDownloadResultsRequest) responseBody(body io.ReadCloser) {
 	f.response = body
 }
@@ -18,9 +18,15 @@ type sandboxInvestigationPackageRequest struct {
 }
 
 func (v *VOne) SandboxInvestigationPackage(id string) *sandboxInvestigationPackageRequest {
-	return &sandboxInvestigationPackageRequest{v.SandboxDownloadResults(id)}
+	f := &sandboxInvestigationPackageRequest{v.SandboxDownloadResults(id)}
+	f.request = f
+	return f
 }
 
 func (s *sandboxInvestigationPackageRequest) url() string {
 	return fmt.Sprintf("/v3.0/sandbox/analysisResults/%s/investigationPackage", s.id)
 }
+
+func (s *sandboxInvestigationPackageRequest) operation() string {
+	return "SandboxInvestigationPackage"
+}
@@ -0,0 +1,82 @@
+package vone_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+)
+
+func TestInvestigationPackage(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	v1 := s.NewVOne()
+	ctx := context.Background()
+	content := "sample content"
+	payload := []byte("dropped payload")
+	payloadSHA1 := sha1.Sum(payload)
+	contentSHA1 := sha1.Sum([]byte(content))
+	s.SetFileVerdict(hex.EncodeToString(contentSHA1[:]), vonetest.Verdict{
+		RiskLevel: vone.RiskLevelHigh,
+		SuspiciousObjects: []vone.SandboxSuspiciousObject{
+			{RiskLevel: vone.RiskLevelHigh, FileSHA1: strings.ToUpper(hex.EncodeToString(payloadSHA1[:]))},
+			{RiskLevel: vone.RiskLevelMedium, Domain: "example.com"},
+		},
+		DroppedFiles: map[string][]byte{
+			"payload.exe": payload,
+			"config.ini":  []byte("[settings]"),
+		},
+	})
+	folder := t.TempDir()
+	filePath := filepath.Join(folder, "sample.exe")
+	if err := os.WriteFile(filePath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	analysis, err := newTestAnalyzer(v1, folder).AnalyzeFile(ctx, filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := analysis.InvestigationPackage("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if len(p.Dropped) != 2 || len(p.PCAPs) != 1 || len(p.Screenshots) != 1 || len(p.Reports) != 1 {
+		t.Errorf("wrong package contents: %+v", p)
+	}
+	for _, entry := range p.Dropped {
+		expected := entry.Name == "dropped/payload.exe"
+		if (entry.SuspiciousObject != nil) != expected {
+			t.Errorf("%s: wrong suspicious object: %v", entry.Name, entry.SuspiciousObject)
+		}
+	}
+	var result vone.SandboxAnalysisResultsResponseItem
+	if err := p.Reports[0].Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result.ID != analysis.ID || result.RiskLevel != vone.RiskLevelHigh {
+		t.Errorf("unexpected report: %+v", result)
+	}
+
+	inMemory, err := v1.SandboxInvestigationPackage(analysis.ID).Open(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matched := inMemory.CrossReference(analysis.SuspiciousObjects)
+	if len(matched) != 1 || matched[0].Digest.SHA1 != hex.EncodeToString(payloadSHA1[:]) {
+		t.Errorf("wrong cross reference: %v", matched)
+	}
+
+	defer func(size int64) { vone.MaxInvestigationPackageSize = size }(vone.MaxInvestigationPackageSize)
+	vone.MaxInvestigationPackageSize = 10
+	if _, err := v1.SandboxInvestigationPackage(analysis.ID).Open(ctx, ""); !errors.Is(err, vone.ErrArchiveLimit) {
+		t.Errorf("expected ErrArchiveLimit, got %v", err)
+	}
+}
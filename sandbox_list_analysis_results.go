@@ -80,10 +80,18 @@ func (f *sandboxListAnalysisResultsRequest) resetPagination() {
 	f.response.NextLink = ""
 }
 
+func (f *sandboxListAnalysisResultsRequest) setNextLink(link string) {
+	f.response.NextLink = link
+}
+
 func (*sandboxListAnalysisResultsRequest) url() string {
 	return "/v3.0/sandbox/analysisResults"
 }
 
+func (*sandboxListAnalysisResultsRequest) operation() string {
+	return "SandboxListAnalysisResults"
+}
+
 func (f *sandboxListAnalysisResultsRequest) uri() string {
 	return f.response.NextLink
 }
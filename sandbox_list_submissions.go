@@ -93,6 +93,10 @@ func (*sandboxSubmissionsRequest) url() string {
 	return "/v3.0/sandbox/tasks"
 }
 
+func (*sandboxSubmissionsRequest) operation() string {
+	return "SandboxListSubmissions"
+}
+
 func (f *sandboxSubmissionsRequest) uri() string {
 	return f.response.NextLink
 }
@@ -109,6 +113,10 @@ func (f *sandboxSubmissionsRequest) resetPagination() {
 	f.response.NextLink = ""
 }
 
+func (f *sandboxSubmissionsRequest) setNextLink(link string) {
+	f.response.NextLink = link
+}
+
 func (f *sandboxSubmissionsRequest) Next(ctx context.Context) (*SandboxSubmissionsResponse, error) {
 	if f.response.NextLink == "" {
 		return nil, io.EOF
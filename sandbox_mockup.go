@@ -2,9 +2,6 @@ package vone
 
 import (
 	"bytes"
-	"crypto/md5"
-	"crypto/sha1"
-	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -73,51 +70,70 @@ func (s *SandboxMockupRAM) EnableFileLogging(path string) error {
 	return nil
 }
 
-func (sm *SandboxMockupRAM) extractFirstPart(body []byte, contentType string) ([]byte, error) {
+// mockupMaxContent - amount of file content kept by mockup to read submission outcome from
+const mockupMaxContent = 1 << 20
+
+// headWriter - keep only first limit bytes of written data
+type headWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	if room := w.limit - w.buf.Len(); room > 0 {
+		w.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+// extractFile - stream multipart body and return beginning of file part
+// content along with hashes of the whole file
+func (sm *SandboxMockupRAM) extractFile(body io.Reader, contentType string) ([]byte, Digest, error) {
 	_, params, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		return nil, err
+		return nil, Digest{}, err
 	}
 	boundary := params["boundary"]
 	if boundary == "" {
-		return nil, errors.New("no boundary")
-	}
-	reader := multipart.NewReader(bytes.NewReader(body), boundary)
-	part, err := reader.NextPart()
-	if err == io.EOF {
-		return nil, errors.New("file content not found")
+		return nil, Digest{}, ErrNoBoundary
 	}
-	if err != nil {
-		return nil, err
+	reader := multipart.NewReader(body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, Digest{}, ErrContentNotFound
+		}
+		if err != nil {
+			return nil, Digest{}, err
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		hasher := newDigestHash()
+		head := &headWriter{limit: mockupMaxContent}
+		size, err := io.Copy(io.MultiWriter(hasher, head), part)
+		if err != nil {
+			return nil, Digest{}, err
+		}
+		// read the rest of body for sender to finish
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return nil, Digest{}, err
+		}
+		sm.logger.Printf("Got file of %d bytes", size)
+		return head.buf.Bytes(), hasher.Digest(), nil
 	}
-	return io.ReadAll(part)
 }
 
 func (sm *SandboxMockupRAM) SubmitFile(f *sandboxSubmitFileRequest) (*SandboxSubmitFileResponse, *SandboxSubmitFileResponseHeaders, error) {
 	sm.logger.Println("SubmitFile")
-	data, err := io.ReadAll(f.request)
+	jsonData, digest, err := sm.extractFile(f.requestBody(), f.contentType())
 	if err != nil {
 		return nil, nil, fmt.Errorf("submit file: %v", err)
 	}
 	re := regexp.MustCompile(`\s+`)
-	strippedData := re.ReplaceAllString(string(data), "")
-	sm.logger.Printf("Got data \"%s\"", strippedData)
-
-	jsonData, err := sm.extractFirstPart(data, f.formDataContentType)
-	if err != nil {
-		return nil, nil, fmt.Errorf("submit file: %v", err)
-	}
 	strippedJsonData := re.ReplaceAllString(string(jsonData), "")
 	sm.logger.Printf("Got JSON data \"%s\"", strippedJsonData)
 
-	md5Hash := md5.Sum(jsonData)
-	sha1Hash := sha1.Sum(jsonData)
-	sha256Hash := sha256.Sum256(jsonData)
-	digest := Digest{
-		MD5:    fmt.Sprintf("%x", md5Hash),
-		SHA1:   fmt.Sprintf("%x", sha1Hash),
-		SHA256: fmt.Sprintf("%x", sha256Hash),
-	}
 	id := uuid.New().String()
 	sm.logger.Printf("SubmitFile (%s)", id)
 	response := SandboxSubmitFileResponse{
@@ -13,6 +13,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -46,6 +47,56 @@ func (s *SandboxSubmissionStatusResponse) GetError() error {
 	return fmt.Errorf("%w: %v: %s", ErrSubmission, s.Error.Code, s.Error.Message)
 }
 
+var _ OperationStatus = &SandboxSubmissionStatusResponse{}
+
+// Done - return whether analysis is finished
+func (s *SandboxSubmissionStatusResponse) Done() bool {
+	return s.Status != StatusRunning
+}
+
+// Err - return error if analysis failed
+func (s *SandboxSubmissionStatusResponse) Err() error {
+	switch s.Status {
+	case StatusSucceeded, StatusRunning:
+		return nil
+	case StatusFailed:
+		if err := s.GetError(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrSubmission, s.Status)
+	default:
+		return fmt.Errorf("unknown status: %v", s.Status)
+	}
+}
+
+// Location - return URL of analysis results
+func (s *SandboxSubmissionStatusResponse) Location() string {
+	return s.ResourceLocation
+}
+
+// SandboxOperation - return operation following submission status till analysis
+// results. operationLocation is Operation-Location header of submission
+// response. If it is empty, status URL is built from submission ID
+func (v *VOne) SandboxOperation(id, operationLocation string) *Operation[SandboxSubmissionStatusResponse, SandboxAnalysisResultsResponseItem] {
+	if operationLocation == "" {
+		operationLocation = fmt.Sprintf("/v3.0/sandbox/tasks/%s", id)
+	}
+	o := NewOperation[SandboxSubmissionStatusResponse, SandboxAnalysisResultsResponseItem](v, operationLocation)
+	if v.mockup != nil {
+		o.fetchStatus = func(ctx context.Context) (*SandboxSubmissionStatusResponse, time.Duration, error) {
+			status, err := v.SandboxSubmissionStatus(id).Do(ctx)
+			if err == nil && status.Status == StatusSucceeded && status.ResourceLocation == "" {
+				status.ResourceLocation = fmt.Sprintf("/v3.0/sandbox/analysisResults/%s", id)
+			}
+			return status, 0, err
+		}
+		o.fetchResult = func(ctx context.Context, _ string) (*SandboxAnalysisResultsResponseItem, error) {
+			return v.SandboxAnalysisResults(id).Do(ctx)
+		}
+	}
+	return o
+}
+
 type sandboxSubmissionStatusRequest struct {
 	baseRequest
 	id       string
@@ -80,6 +131,10 @@ func (f *sandboxSubmissionStatusRequest) url() string {
 	return fmt.Sprintf("/v3.0/sandbox/tasks/%s", f.id)
 }
 
+func (f *sandboxSubmissionStatusRequest) operation() string {
+	return "SandboxSubmissionStatus"
+}
+
 func (f *sandboxSubmissionStatusRequest) responseStruct() any {
 	return &f.response
 }
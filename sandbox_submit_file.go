@@ -11,19 +11,58 @@ package vone
 
 import (
 	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"maps"
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 )
 
 var (
-	ErrFileNotSet = errors.New("file not set")
-	ErrReaderSet  = errors.New("reader already set")
+	ErrFileNotSet      = errors.New("file not set")
+	ErrReaderSet       = errors.New("reader already set")
+	ErrBodyNotReusable = errors.New("request body can not be sent again")
+	ErrDigestMismatch  = errors.New("digest mismatch")
 )
 
+// DigestMismatchError - hashes returned by Vision One differ from hashes of
+// uploaded content (for example, upload was truncated by proxy)
+type DigestMismatchError struct {
+	Local  Digest // Hashes of uploaded content
+	Remote Digest // Hashes returned by Vision One
+}
+
+func (e *DigestMismatchError) Error() string {
+	return fmt.Sprintf("%v: uploaded sha256 %s, got sha256 %s", ErrDigestMismatch, e.Local.SHA256, e.Remote.SHA256)
+}
+
+func (e *DigestMismatchError) Unwrap() error {
+	return ErrDigestMismatch
+}
+
+// Verify - compare hashes with hashes returned by Vision One. Hashes missing
+// in remote digest are not compared. Returns *DigestMismatchError if any of
+// hashes differ
+func (d Digest) Verify(remote Digest) error {
+	match := func(local, remote string) bool {
+		return remote == "" || strings.EqualFold(local, remote)
+	}
+	if match(d.MD5, remote.MD5) && match(d.SHA1, remote.SHA1) && match(d.SHA256, remote.SHA256) {
+		return nil
+	}
+	return &DigestMismatchError{Local: d, Remote: remote}
+}
+
 // SandboxSubmitFileResponse - Submit file to sandbox response JSON format.
 type SandboxSubmitFileResponse struct {
 	ID     string `json:"id"`
@@ -44,20 +83,74 @@ type SandboxSubmitFileResponseHeaders struct {
 	SubmissionExemptionCount int    `header:"TMV1-Submission-Exemption-Count"`
 }
 
+// UploadProgress - callback reporting amount of file bytes sent and total file size (-1 if unknown)
+type UploadProgress func(sent, total int64)
+
+// uploadResult - outcome of streaming of request body
+type uploadResult struct {
+	digest Digest
+	err    error
+}
+
+// digestHash - calculate MD5, SHA1 and SHA256 of written data at once
+type digestHash struct {
+	md5    hash.Hash
+	sha1   hash.Hash
+	sha256 hash.Hash
+}
+
+func newDigestHash() *digestHash {
+	return &digestHash{
+		md5:    md5.New(),
+		sha1:   sha1.New(),
+		sha256: sha256.New(),
+	}
+}
+
+func (h *digestHash) Write(p []byte) (int, error) {
+	h.md5.Write(p)
+	h.sha1.Write(p)
+	h.sha256.Write(p)
+	return len(p), nil
+}
+
+// Digest - return hex encoded hashes of written data
+func (h *digestHash) Digest() Digest {
+	return Digest{
+		MD5:    hex.EncodeToString(h.md5.Sum(nil)),
+		SHA1:   hex.EncodeToString(h.sha1.Sum(nil)),
+		SHA256: hex.EncodeToString(h.sha256.Sum(nil)),
+	}
+}
+
 // sandboxSubmitFileRequest - function to submit file to sandbox
 type sandboxSubmitFileRequest struct {
 	baseRequest
-	request             io.Reader
-	formDataContentType string
-	response            SandboxSubmitFileResponse
-	responseHeaders     SandboxSubmitFileResponseHeaders
+	ctx             context.Context
+	fileName        string
+	reader          io.Reader
+	reopen          func() (io.Reader, error)
+	ownsReader      bool
+	bodyUsed        bool
+	size            int64
+	progress        UploadProgress
+	uploaded        chan uploadResult
+	uploadedDigest  Digest
+	fields          map[string]string
+	boundary        string
+	request         io.Reader
+	response        SandboxSubmitFileResponse
+	responseHeaders SandboxSubmitFileResponseHeaders
 }
 
 var _ vOneRequest = &sandboxSubmitFileRequest{}
 
 // SandboxSubmitFile - return new submit to sandbox file
 func (v *VOne) SandboxSubmitFile() *sandboxSubmitFileRequest {
-	f := &sandboxSubmitFileRequest{}
+	f := &sandboxSubmitFileRequest{
+		fields:   make(map[string]string),
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
 	f.baseRequest.init(v)
 	return f
 }
@@ -77,86 +170,180 @@ func (f *sandboxSubmitFileRequest) SetFilePathAndName(
 	if err != nil {
 		return err
 	}
-	return f.SetReader(ctx, file, fileName)
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if err := f.SetReader(ctx, file, fileName); err != nil {
+		file.Close()
+		return err
+	}
+	f.size = info.Size()
+	f.ownsReader = true
+	f.reopen = func() (io.Reader, error) {
+		return os.Open(filePath)
+	}
+	return nil
 }
 
+// SetReader - submit content of the reader. Reader is not closed. If it
+// implements io.Seeker, it is rewound to the start to upload it again on retry
 func (f *sandboxSubmitFileRequest) SetReader(
 	ctx context.Context,
 	reader io.Reader,
 	fileName string,
 ) error {
-	if f.request != nil {
+	if f.reader != nil {
 		return errors.New("file already set")
 	}
+	f.ctx = ctx
+	f.fileName = fileName
+	f.reader = reader
+	f.size = -1
+	if sized, ok := reader.(interface{ Size() int64 }); ok {
+		f.size = sized.Size()
+	}
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		f.reopen = func() (io.Reader, error) {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return seeker, nil
+		}
+	}
+	return nil
+}
 
+// setBody - start streaming multipart body with form fields and content of the
+// reader. Reader is closed after streaming only if it was opened by request itself
+func (f *sandboxSubmitFileRequest) setBody(reader io.Reader) {
+	owned := f.ownsReader
+	ctx := f.ctx
+	fields := make(map[string]string, len(f.fields))
+	for name, value := range f.fields {
+		fields[name] = value
+	}
 	pr, pw := io.Pipe()
 	writer := multipart.NewWriter(pw)
+	_ = writer.SetBoundary(f.boundary)
+	uploaded := make(chan uploadResult, 1)
 
 	go func() {
-		// Гарантированно закрываем всё
-		defer pw.Close()
-		defer writer.Close()
-
-		if c, ok := reader.(io.Closer); ok {
-			defer c.Close()
+		digest, err := f.writeBody(ctx, writer, reader, fields)
+		if c, ok := reader.(io.Closer); ok && owned {
+			c.Close()
 		}
+		pw.CloseWithError(err)
+		uploaded <- uploadResult{digest: digest, err: err}
+	}()
 
-		// Если контекст уже отменён — даже не начинаем
-		select {
-		case <-ctx.Done():
-			pw.CloseWithError(ctx.Err())
-			return
-		default:
-		}
+	f.request = pr
+	f.uploaded = uploaded
+}
 
-		part, err := writer.CreateFormFile("file", fileName)
-		if err != nil {
-			pw.CloseWithError(err)
-			return
+// writeBody - write form fields and file content hashing it on the fly
+func (f *sandboxSubmitFileRequest) writeBody(ctx context.Context, writer *multipart.Writer, reader io.Reader, fields map[string]string) (Digest, error) {
+	// Если контекст уже отменён — даже не начинаем
+	if err := ctx.Err(); err != nil {
+		return Digest{}, err
+	}
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if err := writer.WriteField(name, fields[name]); err != nil {
+			return Digest{}, err
 		}
-
-		buf := make([]byte, 32*1024)
-		for {
-			select {
-			case <-ctx.Done():
-				pw.CloseWithError(ctx.Err())
-				return
-			default:
-			}
-
-			n, err := reader.Read(buf)
-			if n > 0 {
-				if _, werr := part.Write(buf[:n]); werr != nil {
-					pw.CloseWithError(werr)
-					return
-				}
+	}
+	part, err := writer.CreateFormFile("file", f.fileName)
+	if err != nil {
+		return Digest{}, err
+	}
+	hasher := newDigestHash()
+	output := io.MultiWriter(part, hasher)
+	sent := int64(0)
+	buf := make([]byte, 32*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return Digest{}, err
+		}
+		n, err := reader.Read(buf)
+		if n > 0 {
+			if _, werr := output.Write(buf[:n]); werr != nil {
+				return Digest{}, werr
 			}
-			if err != nil {
-				if err != io.EOF {
-					pw.CloseWithError(err)
-				}
-				return
+			sent += int64(n)
+			if f.progress != nil {
+				f.progress(sent, f.size)
 			}
 		}
-	}()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Digest{}, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return Digest{}, err
+	}
+	return hasher.Digest(), nil
+}
 
-	f.request = pr
-	f.formDataContentType = writer.FormDataContentType()
+// verify - wait for body streaming to finish and compare digest of sent
+// content with digest returned by Vision One
+func (f *sandboxSubmitFileRequest) verify(ctx context.Context, response *SandboxSubmitFileResponse) error {
+	if f.uploaded == nil {
+		return nil
+	}
+	select {
+	case upload := <-f.uploaded:
+		if upload.err != nil {
+			return fmt.Errorf("submit file: %w", upload.err)
+		}
+		f.uploadedDigest = upload.digest
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+	if err := f.uploadedDigest.Verify(Digest(response.Digest)); err != nil {
+		return fmt.Errorf("submit file: %w", err)
+	}
 	return nil
 }
 
+// UploadedDigest - return hashes of file content calculated while it was
+// sent by Do and verified against hashes returned by Vision One. Empty if
+// file was not sent completely
+func (f *sandboxSubmitFileRequest) UploadedDigest() Digest {
+	return f.uploadedDigest
+}
+
+// SetProgress - set callback called each time next chunk of file is sent
+// with amount of bytes sent so far and total file size (-1 if unknown).
+// On retry, progress starts from zero again
+func (f *sandboxSubmitFileRequest) SetProgress(progress UploadProgress) *sandboxSubmitFileRequest {
+	f.progress = progress
+	return f
+}
+
+// setField - add base64 encoded multipart form field
+func (s *sandboxSubmitFileRequest) setField(name, value string) {
+	s.fields[name] = base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+// SetDocumentPassword - set password to open protected document
 func (s *sandboxSubmitFileRequest) SetDocumentPassword(documentPassword string) *sandboxSubmitFileRequest {
-	s.setParameter("documentPassword", documentPassword)
+	s.setField("documentPassword", documentPassword)
 	return s
 }
 
+// SetArchivePassword - set password to extract protected archive
 func (s *sandboxSubmitFileRequest) SetArchivePassword(archivePassword string) *sandboxSubmitFileRequest {
-	s.setParameter("archivePassword", archivePassword)
+	s.setField("archivePassword", archivePassword)
 	return s
 }
 
+// SetArguments - set command line arguments to run sample with
 func (s *sandboxSubmitFileRequest) SetArguments(arguments string) *sandboxSubmitFileRequest {
-	s.setParameter("arguments", arguments)
+	s.setField("arguments", arguments)
 	return s
 }
 
@@ -164,17 +351,21 @@ func (f *sandboxSubmitFileRequest) Do(ctx context.Context) (*SandboxSubmitFileRe
 	if err := f.checkUsed(); err != nil {
 		return nil, nil, fmt.Errorf("submit file: %w", err)
 	}
-	if f.request == nil {
+	if f.reader == nil {
 		return nil, nil, fmt.Errorf("submit file: %w", ErrFileNotSet)
 	}
 
 	if f.vone.mockup != nil {
-		return f.vone.mockup.SubmitFile(f)
+		response, headers, err := f.vone.mockup.SubmitFile(f)
+		if err != nil {
+			return nil, nil, err
+		}
+		return response, headers, f.verify(ctx, response)
 	}
 	if err := f.vone.call(ctx, f); err != nil {
 		return nil, nil, err
 	}
-	return &f.response, &f.responseHeaders, nil
+	return &f.response, &f.responseHeaders, f.verify(ctx, &f.response)
 }
 
 func (f *sandboxSubmitFileRequest) method() string {
@@ -185,12 +376,33 @@ func (s *sandboxSubmitFileRequest) url() string {
 	return "/v3.0/sandbox/files/analyze"
 }
 
+func (s *sandboxSubmitFileRequest) operation() string {
+	return "SandboxSubmitFile"
+}
+
+// requestBody - return multipart body. Each next call (on retry) streams file content again
 func (f *sandboxSubmitFileRequest) requestBody() io.Reader {
+	if !f.bodyUsed {
+		f.bodyUsed = true
+		f.setBody(f.reader)
+		return f.request
+	}
+	pr, pw := io.Pipe()
+	if f.reopen == nil {
+		pw.CloseWithError(ErrBodyNotReusable)
+		return pr
+	}
+	reader, err := f.reopen()
+	if err != nil {
+		pw.CloseWithError(err)
+		return pr
+	}
+	f.setBody(reader)
 	return f.request
 }
 
 func (f *sandboxSubmitFileRequest) contentType() string {
-	return f.formDataContentType
+	return "multipart/form-data; boundary=" + f.boundary
 }
 
 func (f *sandboxSubmitFileRequest) responseStruct() any {
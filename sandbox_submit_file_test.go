@@ -0,0 +1,126 @@
+package vone_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+)
+
+func TestDigestVerify(t *testing.T) {
+	local := vone.Digest{MD5: "aa", SHA1: "bb", SHA256: "cc"}
+	for _, remote := range []vone.Digest{local, {SHA1: "BB"}, {}} {
+		if err := local.Verify(remote); err != nil {
+			t.Errorf("%+v: %v", remote, err)
+		}
+	}
+	for _, remote := range []vone.Digest{{MD5: "aa", SHA1: "bb", SHA256: "cd"}, {SHA1: "b"}} {
+		err := local.Verify(remote)
+		var mismatch *vone.DigestMismatchError
+		if !errors.Is(err, vone.ErrDigestMismatch) || !errors.As(err, &mismatch) || mismatch.Remote != remote {
+			t.Errorf("%+v: expected vone.ErrDigestMismatch, got %v", remote, err)
+		}
+	}
+}
+
+func TestSubmitFilePasswords(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	v1 := s.NewVOne()
+	ctx := context.Background()
+	submit := v1.SandboxSubmitFile()
+	if err := submit.SetReader(ctx, strings.NewReader("protected content"), "samples.zip"); err != nil {
+		t.Fatal(err)
+	}
+	response, _, err := submit.SetArchivePassword("infected").SetDocumentPassword("secret").SetArguments("--verbose").Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := s.SubmissionFields(response.ID)
+	if fields["archivePassword"] != "infected" || fields["documentPassword"] != "secret" || fields["arguments"] != "--verbose" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+}
+
+func TestSubmitFileStreaming(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	v1 := s.NewVOne()
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("large installer "), 1<<16)
+	submit := v1.SandboxSubmitFile()
+	if err := submit.SetReader(ctx, bytes.NewReader(content), "setup.exe"); err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	var sent, total int64
+	submit.SetProgress(func(s, t int64) {
+		calls++
+		sent, total = s, t
+	})
+	response, _, err := submit.Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls < 2 || sent != int64(len(content)) || total != int64(len(content)) {
+		t.Errorf("unexpected progress: %d calls, %d of %d bytes", calls, sent, total)
+	}
+	sum := sha1.Sum(content)
+	if uploaded := submit.UploadedDigest(); uploaded.SHA1 != hex.EncodeToString(sum[:]) || uploaded != vone.Digest(response.Digest) {
+		t.Errorf("unexpected uploaded digest: %+v, response: %+v", uploaded, response.Digest)
+	}
+}
+
+func TestSubmitFileDigestMismatch(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	v1 := s.NewVOne()
+	// emulate proxy damaging uploaded content
+	v1.Use(func(next vone.CallFunc) vone.CallFunc {
+		return func(call *vone.Call) error {
+			if call.Operation == "SandboxSubmitFile" {
+				body, err := io.ReadAll(call.Request.Body)
+				if err != nil {
+					return err
+				}
+				body = bytes.ReplaceAll(body, []byte("malicious"), []byte("malicioux"))
+				call.Request.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			return next(call)
+		}
+	})
+	folder := t.TempDir()
+	samplePath := filepath.Join(folder, "sample.exe")
+	if err := os.WriteFile(samplePath, []byte("malicious content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	submit := v1.SandboxSubmitFile()
+	if err := submit.SetFilePath(ctx, samplePath); err != nil {
+		t.Fatal(err)
+	}
+	response, _, err := submit.Do(ctx)
+	var mismatch *vone.DigestMismatchError
+	if !errors.Is(err, vone.ErrDigestMismatch) || !errors.As(err, &mismatch) {
+		t.Fatalf("expected ErrDigestMismatch, got %v", err)
+	}
+	if response == nil || response.ID == "" || mismatch.Remote.SHA1 != response.Digest.SHA1 || mismatch.Local.SHA1 == mismatch.Remote.SHA1 {
+		t.Errorf("unexpected response %+v for %v", response, mismatch)
+	}
+	analysis, err := newTestAnalyzer(v1, folder).AnalyzeFile(ctx, samplePath)
+	if !errors.Is(err, vone.ErrDigestMismatch) {
+		t.Errorf("expected ErrDigestMismatch, got %v", err)
+	}
+	if analysis.ID == "" || analysis.Result != nil {
+		t.Errorf("unexpected analysis: %+v", analysis)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 )
 
 type (
@@ -43,6 +44,27 @@ type (
 	SandboxSubmitURLsToSandboxResponse []SubmitURLsToSandboxStruct
 )
 
+// GetError - return error if URL was not accepted
+func (s *SubmitURLsToSandboxStruct) GetError() error {
+	if GetHTTPCodeRange(s.Status) == HTTPCodeSuccessRange {
+		return nil
+	}
+	return &HTTPError{
+		Status: s.Status,
+		Err:    fmt.Errorf("%w: %s", ErrSubmission, s.Body.Error.Code),
+	}
+}
+
+// OperationLocation - return URL of submission status
+func (s *SubmitURLsToSandboxStruct) OperationLocation() string {
+	for _, header := range s.Headers {
+		if strings.EqualFold(header.Name, "Operation-Location") {
+			return header.Value
+		}
+	}
+	return ""
+}
+
 type sandboxSubmitURLsRequest struct {
 	baseRequest
 	request         SubmitURLsToSandboxRequest
@@ -86,6 +108,10 @@ func (s *sandboxSubmitURLsRequest) url() string {
 	return "/v3.0/sandbox/urls/analyze"
 }
 
+func (s *sandboxSubmitURLsRequest) operation() string {
+	return "SandboxSubmitURLs"
+}
+
 func (f *sandboxSubmitURLsRequest) requestBody() io.Reader {
 	data, _ := json.Marshal(f.request)
 	return bytes.NewBuffer(data)
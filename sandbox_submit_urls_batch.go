@@ -0,0 +1,180 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Sandbox API capabilities
+
+	sandbox_submit_urls_batch.go - submit any amount of URLs in API sized chunks
+*/
+
+package vone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MaxURLsPerRequest - maximum amount of URLs accepted by single submit URLs request
+const MaxURLsPerRequest = 10
+
+// ErrInvalidURL - URL can not be submitted to sandbox
+var ErrInvalidURL = errors.New("invalid URL")
+
+// defaultPorts - ports removed from URL by NormalizeURL
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// NormalizeURL - return URL in canonical form: http scheme is added if missing,
+// scheme and host are lowercased, default port and fragment are removed and
+// empty path is replaced by "/"
+func NormalizeURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", fmt.Errorf("%w: empty", ErrInvalidURL)
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if _, ok := defaultPorts[u.Scheme]; !ok {
+		return "", fmt.Errorf("%w: unsupported scheme: %s", ErrInvalidURL, rawURL)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("%w: missing host: %s", ErrInvalidURL, rawURL)
+	}
+	u.Host = strings.ToLower(u.Host)
+	if u.Port() == defaultPorts[u.Scheme] {
+		u.Host = u.Hostname()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String(), nil
+}
+
+// URLSubmission - outcome of submission of one URL
+type URLSubmission struct {
+	URL               string                            // URL as provided
+	Normalized        string                            // URL submitted to sandbox
+	ID                string                            // Submission ID
+	OperationLocation string                            // Operation-Location URL of submission status
+	Digest            Digest                            // Digest of URL
+	Quota             *SandboxSubmitFileResponseHeaders // Submission quota after request that submitted URL
+	Err               error                             // Error of URL submission
+}
+
+// URLBatchSubmitter - submit any amount of URLs splitting them into chunks of
+// MaxURLsPerRequest URLs. Usage:
+//
+//	submissions := vone.NewURLBatchSubmitter(v1).Submit(ctx, urls)
+//	for _, s := range submissions {
+//		if s.Err != nil {
+//	...
+type URLBatchSubmitter struct {
+	// Concurrency - maximum amount of simultaneous requests
+	Concurrency int
+
+	vone *VOne
+}
+
+// NewURLBatchSubmitter - create submitter running up to 4 requests simultaneously
+func NewURLBatchSubmitter(v1 *VOne) *URLBatchSubmitter {
+	return &URLBatchSubmitter{
+		Concurrency: 4,
+		vone:        v1,
+	}
+}
+
+// Submit - normalize and deduplicate URLs and submit them to sandbox. Return
+// outcome for each of given URLs in the same order. Duplicate URLs get the
+// same submission
+func (b *URLBatchSubmitter) Submit(ctx context.Context, urls []string) []URLSubmission {
+	result := make([]URLSubmission, len(urls))
+	indexes := make(map[string][]int)
+	var unique []string
+	for i, rawURL := range urls {
+		result[i].URL = rawURL
+		normalized, err := NormalizeURL(rawURL)
+		if err != nil {
+			result[i].Err = err
+			continue
+		}
+		result[i].Normalized = normalized
+		if _, ok := indexes[normalized]; !ok {
+			unique = append(unique, normalized)
+		}
+		indexes[normalized] = append(indexes[normalized], i)
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, max(b.Concurrency, 1))
+	for chunk := range slices.Chunk(unique, MaxURLsPerRequest) {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			submissions := b.submit(ctx, chunk)
+			mu.Lock()
+			defer mu.Unlock()
+			for normalized, submission := range submissions {
+				for _, i := range indexes[normalized] {
+					submission.URL = result[i].URL
+					result[i] = submission
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return result
+}
+
+// submit - submit up to MaxURLsPerRequest URLs and return submission for each of them
+func (b *URLBatchSubmitter) submit(ctx context.Context, chunk []string) map[string]URLSubmission {
+	result := make(map[string]URLSubmission, len(chunk))
+	response, headers, err := b.vone.SandboxSubmitURLs().AddURLs(chunk).Do(ctx)
+	for _, normalized := range chunk {
+		s := URLSubmission{
+			Normalized: normalized,
+			Quota:      headers,
+			Err:        err,
+		}
+		if err == nil {
+			s.Err = fmt.Errorf("%w: %s: missing in response", ErrSubmission, normalized)
+		}
+		result[normalized] = s
+	}
+	if err != nil {
+		return result
+	}
+	for i, entry := range response {
+		// entries follow order of request, but rely on returned URL if amounts differ
+		normalized := entry.Body.URL
+		if len(response) == len(chunk) {
+			normalized = chunk[i]
+		}
+		s, ok := result[normalized]
+		if !ok {
+			continue
+		}
+		s.ID = entry.Body.ID
+		s.OperationLocation = entry.OperationLocation()
+		s.Digest = entry.Body.Digest
+		s.Err = entry.GetError()
+		result[normalized] = s
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package vone_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"example.com", "http://example.com/"},
+		{" HTTPS://Example.COM:443/Path?q=1#top ", "https://example.com/Path?q=1"},
+		{"http://example.com:8080", "http://example.com:8080/"},
+		{"http://example.com:80/a", "http://example.com/a"},
+	}
+	for _, tc := range testCases {
+		actual, err := vone.NormalizeURL(tc.url)
+		if err != nil {
+			t.Errorf("%s: %v", tc.url, err)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.url, tc.expected, actual)
+		}
+	}
+	for _, url := range []string{"", "ftp://example.com/", "http://", "http://exa mple.com/"} {
+		if _, err := vone.NormalizeURL(url); !errors.Is(err, vone.ErrInvalidURL) {
+			t.Errorf("%q: expected vone.ErrInvalidURL, got %v", url, err)
+		}
+	}
+}
+
+func TestURLBatchSubmitter(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.SetDailyReserve(22)
+	var urls []string
+	for i := 0; i < 25; i++ {
+		urls = append(urls, fmt.Sprintf("http://example.com/%d", i))
+	}
+	urls = append(urls, "EXAMPLE.com/3", "ftp://example.com/")
+	submitter := vone.NewURLBatchSubmitter(s.NewVOne())
+	submitter.Concurrency = 2
+	submissions := submitter.Submit(context.Background(), urls)
+	if len(submissions) != len(urls) {
+		t.Fatalf("expected %d submissions, got %d", len(urls), len(submissions))
+	}
+	if actual := s.RequestCount("/v3.0/sandbox/urls/analyze"); actual != 3 {
+		t.Errorf("expected 3 requests, got %d", actual)
+	}
+	accepted := 0
+	overQuota := 0
+	for i, submission := range submissions {
+		if submission.URL != urls[i] {
+			t.Errorf("%d: expected URL %s, got %s", i, urls[i], submission.URL)
+		}
+		var httpErr *vone.HTTPError
+		if errors.As(submission.Err, &httpErr) && errors.Is(httpErr, vone.ErrSubmission) {
+			overQuota++
+		}
+		if submission.Err != nil {
+			continue
+		}
+		accepted++
+		sum := sha1.Sum([]byte(submission.Normalized))
+		if submission.Digest.SHA1 != hex.EncodeToString(sum[:]) {
+			t.Errorf("%s: submission of other URL: %s", submission.URL, submission.ID)
+		}
+	}
+	// 22 unique URLs fit quota and the duplicate shares submission with the original
+	if accepted != 23 {
+		t.Errorf("expected 23 accepted URLs, got %d", accepted)
+	}
+	if submissions[25].ID != submissions[3].ID || submissions[25].Err != submissions[3].Err {
+		t.Errorf("duplicate got other submission: %s != %s", submissions[25].ID, submissions[3].ID)
+	}
+	if !errors.Is(submissions[26].Err, vone.ErrInvalidURL) {
+		t.Errorf("expected ErrInvalidURL, got %v", submissions[26].Err)
+	}
+	if overQuota != 3 {
+		t.Errorf("expected 3 URLs over quota, got %d", overQuota)
+	}
+}
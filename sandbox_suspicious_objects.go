@@ -55,6 +55,10 @@ func (f *sandboxSuspiciousObjectsRequest) url() string {
 	return fmt.Sprintf("/v3.0/sandbox/analysisResults/%s/suspiciousObjects", f.id)
 }
 
+func (f *sandboxSuspiciousObjectsRequest) operation() string {
+	return "SandboxSuspiciousObjects"
+}
+
 func (f *sandboxSuspiciousObjectsRequest) responseStruct() any {
 	return &f.response
 }
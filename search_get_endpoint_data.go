@@ -53,9 +53,6 @@ type searchEndPointDataRequest struct {
 
 // Do - run request
 func (f *searchEndPointDataRequest) Do(ctx context.Context) (*SearchEndPointDataResponse, error) {
-	if err := f.checkUsed(); err != nil {
-		return nil, err
-	}
 	if err := f.vone.call(ctx, f); err != nil {
 		return nil, err
 	}
@@ -102,13 +99,22 @@ func (f *searchEndPointDataRequest) resetPagination() {
 	f.response.NextLink = ""
 }
 
+func (f *searchEndPointDataRequest) setNextLink(link string) {
+	f.response.NextLink = link
+}
+
+func (s *searchEndPointDataRequest) uri() string {
+	return s.response.NextLink
+}
+
 func (s *searchEndPointDataRequest) url() string {
-	if s.response.NextLink != "" {
-		return s.response.NextLink
-	}
 	return "/v3.0/eiqs/endpoints"
 }
 
+func (s *searchEndPointDataRequest) operation() string {
+	return "SearchEndPointData"
+}
+
 func (f *searchEndPointDataRequest) responseStruct() any {
 	return &f.response
 }
@@ -0,0 +1,287 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	shared_rate_limiter.go - rate limit budget shared by several processes through SQL database
+*/
+
+package vone
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SharedRateLimiter - rate limiter that keeps budget of each endpoint family in
+// SQL database (SQLite or PostgreSQL), so several processes using the same API
+// key do not surpass tenant rate limit together. Each request takes one token
+// of the current window. Limit, window and remaining requests are updated from
+// RateLimit-* headers of every response received by any of the processes
+type SharedRateLimiter struct {
+	// MaxThrottles - amount of consecutive 429 responses to retry
+	MaxThrottles int
+
+	dbPath    string
+	db        *sql.DB
+	stop      chan struct{}
+	family    func(*http.Request) string
+	now       func() time.Time
+	mu        sync.Mutex
+	stats     map[string]RateLimiterStats
+	throttles int
+	err       error
+}
+
+var _ EndpointRateLimiter = &SharedRateLimiter{}
+
+const (
+	// probeTimeout - how long requests wait for the first response of endpoint family
+	// providing rate limit
+	probeTimeout = 10 * time.Second
+	// probePoll - how often waiting requests check whether rate limit became known
+	probePoll = 50 * time.Millisecond
+	// resetResolution - RateLimit-Reset is given in seconds, so reset times
+	// of responses from the same window can differ up to this duration
+	resetResolution = time.Second
+)
+
+// NewSharedRateLimiter - create rate_limits table if needed and return rate limiter
+// using it. Closing stop channel aborts waiting requests. stop can be nil
+func NewSharedRateLimiter(db *sql.DB, dbPath string, stop chan struct{}) (*SharedRateLimiter, error) {
+	stmt := `CREATE TABLE IF NOT EXISTS rate_limits (
+		family TEXT NOT NULL PRIMARY KEY,
+		limit_count INTEGER NOT NULL DEFAULT 0,
+		window_ms BIGINT NOT NULL DEFAULT 0,
+		remaining INTEGER NOT NULL DEFAULT 0,
+		reset_at BIGINT NOT NULL DEFAULT 0
+		)`
+	if _, err := db.Exec(stmt); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbPath, err)
+	}
+	return &SharedRateLimiter{
+		MaxThrottles: DefaultMaxThrottles,
+		dbPath:       dbPath,
+		db:           db,
+		stop:         stop,
+		family:       EndpointFamily,
+		now:          time.Now,
+		stats:        make(map[string]RateLimiterStats),
+	}, nil
+}
+
+// SetFamily - set function grouping requests sharing the same rate limit.
+// Default is EndpointFamily
+func (l *SharedRateLimiter) SetFamily(family func(*http.Request) string) *SharedRateLimiter {
+	l.family = family
+	return l
+}
+
+// ShouldAbort - check whether stop channel is closed
+func (l *SharedRateLimiter) ShouldAbort() bool {
+	select {
+	case <-l.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// CheckError - request to send request again if it was throttled
+func (l *SharedRateLimiter) CheckError(err error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return checkThrottle(err, &l.throttles, l.MaxThrottles)
+}
+
+// Wait - block until token of shared budget is taken
+func (l *SharedRateLimiter) Wait(req *http.Request) error {
+	ctx := req.Context()
+	family := l.family(req)
+	var waited time.Duration
+	defer func() {
+		l.updateStats(family, func(s *RateLimiterStats) {
+			s.Requests++
+			if waited > 0 {
+				s.Waits++
+				s.WaitTime += waited
+			}
+		})
+	}()
+	for {
+		resetAt, err := l.take(ctx, family)
+		if err != nil {
+			return err
+		}
+		if resetAt.IsZero() {
+			return nil
+		}
+		started := time.Now()
+		err = sleep(ctx, max(resetAt.Sub(l.now()), time.Millisecond), l.stop)
+		waited += time.Since(started)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// take - take token from the budget. Return zero time on success or time
+// when budget is renewed
+func (l *SharedRateLimiter) take(ctx context.Context, family string) (time.Time, error) {
+	now := l.now().UnixMilli()
+	taken, err := l.exec(ctx, "Wait take", `UPDATE rate_limits SET remaining = remaining - 1
+		WHERE family = $1 AND remaining > 0 AND reset_at > $2`, family, now)
+	if err != nil || taken {
+		return time.Time{}, err
+	}
+	renewed, err := l.exec(ctx, "Wait renew", `UPDATE rate_limits SET remaining = limit_count - 1, reset_at = $2 + window_ms
+		WHERE family = $1 AND limit_count > 0 AND reset_at <= $2`, family, now)
+	if err != nil || renewed {
+		return time.Time{}, err
+	}
+	var limit int
+	var resetAt int64
+	row := l.db.QueryRowContext(ctx, "SELECT limit_count, reset_at FROM rate_limits WHERE family = $1", family)
+	err = row.Scan(&limit, &resetAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// first request of the family probes limit while others wait for its response
+		inserted, err := l.exec(ctx, "Wait insert", `INSERT INTO rate_limits (family, reset_at) VALUES ($1, $2)
+			ON CONFLICT (family) DO NOTHING`, family, now+probeTimeout.Milliseconds())
+		if err != nil || inserted {
+			return time.Time{}, err
+		}
+		return time.UnixMilli(now), nil
+	}
+	if err != nil {
+		return time.Time{}, l.error("Wait Scan", err)
+	}
+	if limit == 0 {
+		if resetAt <= now {
+			// Vision One did not provide limit
+			return time.Time{}, nil
+		}
+		return time.UnixMilli(min(resetAt, now+probePoll.Milliseconds())), nil
+	}
+	return time.UnixMilli(resetAt), nil
+}
+
+// Observe - update shared budget using response headers
+func (l *SharedRateLimiter) Observe(req *http.Request, resp *http.Response) {
+	family := l.family(req)
+	ctx := context.WithoutCancel(req.Context())
+	now := l.now()
+	header := resp.Header
+	limit := parseInt(header.Get("RateLimit-Limit"))
+	window := parseInt(header.Get("RateLimit-Window"))
+	remaining, err := strconv.Atoi(header.Get("RateLimit-Remaining"))
+	if limit > 0 && window > 0 && err == nil {
+		resetAt := int64(0)
+		if reset := parseInt(header.Get("RateLimit-Reset")); reset > 0 {
+			resetAt = now.Add(time.Duration(reset) * time.Second).UnixMilli()
+		} else if remaining > 0 {
+			resetAt = now.Add(time.Duration(window) * time.Second).UnixMilli()
+		}
+		// other requests of the same window can be in flight, so remaining is
+		// only lowered, unless response comes from the next window
+		_, err := l.exec(ctx, "Observe", `UPDATE rate_limits SET
+			remaining = CASE WHEN limit_count = 0 OR remaining > $4 OR CAST($5 AS BIGINT) > reset_at + $6 THEN $4 ELSE remaining END,
+			limit_count = $2,
+			window_ms = $3,
+			reset_at = CASE WHEN CAST($5 AS BIGINT) > 0 THEN CAST($5 AS BIGINT) ELSE reset_at END
+			WHERE family = $1`, family, limit, int64(window)*1000, remaining, resetAt, resetResolution.Milliseconds())
+		l.setError(err)
+	} else {
+		_, err := l.exec(ctx, "Observe", `UPDATE rate_limits SET reset_at = 0
+			WHERE family = $1 AND limit_count = 0`, family)
+		l.setError(err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+	l.updateStats(family, func(s *RateLimiterStats) {
+		s.Throttles++
+	})
+	resetAt := now.Add(throttlePause(header)).UnixMilli()
+	_, err = l.exec(ctx, "Observe throttle", `UPDATE rate_limits SET
+		remaining = 0,
+		reset_at = CASE WHEN reset_at < $2 THEN $2 ELSE reset_at END
+		WHERE family = $1`, family, resetAt)
+	l.setError(err)
+}
+
+// Budget - return limit, remaining requests and time of window reset shared by all processes.
+// Zero limit means it is not known yet
+func (l *SharedRateLimiter) Budget(ctx context.Context, family string) (limit, remaining int, reset time.Time, err error) {
+	var resetAt int64
+	row := l.db.QueryRowContext(ctx, "SELECT limit_count, remaining, reset_at FROM rate_limits WHERE family = $1", family)
+	err = row.Scan(&limit, &remaining, &resetAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, 0, time.Time{}, l.error("Budget", err)
+	}
+	return limit, remaining, time.UnixMilli(resetAt), nil
+}
+
+// Stats - return statistics of this process for each endpoint family
+func (l *SharedRateLimiter) Stats() map[string]RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := make(map[string]RateLimiterStats, len(l.stats))
+	for family, s := range l.stats {
+		result[family] = s
+	}
+	return result
+}
+
+func (l *SharedRateLimiter) updateStats(family string, update func(*RateLimiterStats)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.stats[family]
+	update(&s)
+	l.stats[family] = s
+}
+
+// Err - return the last error of updating shared budget by response headers.
+// Such errors do not fail requests, so budget can be stale until next response
+func (l *SharedRateLimiter) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// setError - keep error to be returned by Err
+func (l *SharedRateLimiter) setError(err error) {
+	if err == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.err = err
+}
+
+// exec - execute statement and return whether any row was affected
+func (l *SharedRateLimiter) exec(ctx context.Context, message string, stmt string, args ...any) (bool, error) {
+	result, err := l.db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return false, l.error(message, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, l.error(message, err)
+	}
+	return affected > 0, nil
+}
+
+func (l *SharedRateLimiter) error(message string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %s: %w", l.dbPath, message, err)
+}
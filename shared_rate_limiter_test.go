@@ -0,0 +1,178 @@
+package vone
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestSharedRateLimiter(t *testing.T) {
+	folder := "testing/shared"
+	if err := os.RemoveAll(folder); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(folder, 0775); err != nil {
+		t.Fatal(err)
+	}
+	dbPath := filepath.Join(folder, "limits.sqlite3")
+	newLimiter := func() *SharedRateLimiter {
+		db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		l, err := NewSharedRateLimiter(db, dbPath, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+	now := time.Now()
+	first, second := newLimiter(), newLimiter()
+	first.now = func() time.Time { return now }
+	second.now = first.now
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := (&http.Request{URL: &url.URL{Path: "/v3.0/sandbox/files/analyze"}}).WithContext(ctx)
+	if err := first.Wait(req); err != nil {
+		t.Fatal(err)
+	}
+	header := make(http.Header)
+	header.Set("RateLimit-Limit", "3")
+	header.Set("RateLimit-Window", "60")
+	header.Set("RateLimit-Remaining", "2")
+	header.Set("RateLimit-Reset", "30")
+	first.Observe(req, &http.Response{StatusCode: http.StatusOK, Header: header})
+	if err := first.Wait(req); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Wait(req); err != nil {
+		t.Fatal(err)
+	}
+	limit, remaining, reset, err := second.Budget(ctx, "sandbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit != 3 || remaining != 0 || !reset.Equal(time.UnixMilli(now.Add(30*time.Second).UnixMilli())) {
+		t.Errorf("unexpected budget: %d %d %v", limit, remaining, reset)
+	}
+
+	// budget is exhausted, so next request waits for window reset
+	waitCtx, waitCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer waitCancel()
+	if err := second.Wait(req.WithContext(waitCtx)); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	now = now.Add(31 * time.Second)
+	if err := second.Wait(req); err != nil {
+		t.Fatal(err)
+	}
+	if _, remaining, _, _ := first.Budget(ctx, "sandbox"); remaining != 2 {
+		t.Errorf("expected 2 remaining after reset, got %d", remaining)
+	}
+
+	header.Set("RateLimit-Remaining", "0")
+	header.Set("Retry-After", "10")
+	second.Observe(req, &http.Response{StatusCode: http.StatusTooManyRequests, Header: header})
+	if _, remaining, _, _ := first.Budget(ctx, "sandbox"); remaining != 0 {
+		t.Errorf("expected no remaining requests after 429, got %d", remaining)
+	}
+	if s := second.Stats()["sandbox"]; s.Requests != 3 || s.Throttles != 1 || s.Waits != 1 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+}
+
+func TestSharedRateLimiterObserveError(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "limits.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	l, err := NewSharedRateLimiter(db, dbPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// fail only updates made by Observe
+	_, err = db.Exec(`CREATE TRIGGER fail_observe BEFORE UPDATE ON rate_limits
+		WHEN NEW.limit_count = 7 BEGIN SELECT RAISE(ABORT, 'observe failed'); END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := (&http.Request{URL: &url.URL{Path: "/v3.0/sandbox/files/analyze"}}).WithContext(context.Background())
+	if err := l.Wait(req); err != nil {
+		t.Fatal(err)
+	}
+	header := make(http.Header)
+	header.Set("RateLimit-Limit", "3")
+	header.Set("RateLimit-Window", "60")
+	header.Set("RateLimit-Remaining", "2")
+	l.Observe(req, &http.Response{StatusCode: http.StatusOK, Header: header})
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	header.Set("RateLimit-Limit", "7")
+	l.Observe(req, &http.Response{StatusCode: http.StatusOK, Header: header})
+	if l.Err() == nil {
+		t.Fatal("observe error is not kept")
+	}
+	if err := l.Wait(req); err != nil {
+		t.Errorf("observe error fails next request: %v", err)
+	}
+}
+
+func TestSharedRateLimiterWindowRollover(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "limits.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	l, err := NewSharedRateLimiter(db, dbPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+	req := (&http.Request{URL: &url.URL{Path: "/v3.0/sandbox/files/analyze"}}).WithContext(ctx)
+	if err := l.Wait(req); err != nil {
+		t.Fatal(err)
+	}
+	observe := func(remaining, reset string) {
+		header := make(http.Header)
+		header.Set("RateLimit-Limit", "10")
+		header.Set("RateLimit-Window", "60")
+		header.Set("RateLimit-Remaining", remaining)
+		header.Set("RateLimit-Reset", reset)
+		l.Observe(req, &http.Response{StatusCode: http.StatusOK, Header: header})
+		if err := l.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	observe("1", "30")
+	// late response of the same window does not raise remaining
+	now = now.Add(500 * time.Millisecond)
+	observe("5", "30")
+	if _, remaining, _, _ := l.Budget(ctx, "sandbox"); remaining != 1 {
+		t.Errorf("expected 1 remaining in the same window, got %d", remaining)
+	}
+	// response from the next window brings its budget
+	now = now.Add(31 * time.Second)
+	observe("9", "59")
+	limit, remaining, reset, err := l.Budget(ctx, "sandbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit != 10 || remaining != 9 || !reset.Equal(time.UnixMilli(now.Add(59*time.Second).UnixMilli())) {
+		t.Errorf("unexpected budget after window rollover: %d %d %v", limit, remaining, reset)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Sandbox API capabilities
+
+	stix.go - export sandbox verdict as STIX 2.1 bundle
+*/
+
+package vone
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// STIXSpecVersion - version of STIX specification of exported objects
+	STIXSpecVersion = "2.1"
+	// STIXProduct - product name of malware analysis objects
+	STIXProduct = "Trend Micro Vision One Sandbox"
+	// stixTimeFormat - STIX timestamp format with milliseconds precision
+	stixTimeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// stixNamespace - namespace for deterministic STIX identifiers defined by STIX 2.1 specification
+var stixNamespace = uuid.MustParse("00abedb4-aa42-466c-9c01-fed23315a9b7")
+
+// STIXBundle - STIX 2.1 bundle
+type STIXBundle struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id"`
+	Objects []*STIXObject `json:"objects"`
+}
+
+// STIXObject - STIX 2.1 object. Only properties of objects produced from
+// sandbox analysis are provided: file, malware-analysis and indicator
+type STIXObject struct {
+	Type        string   `json:"type"`
+	SpecVersion string   `json:"spec_version"`
+	ID          string   `json:"id"`
+	Created     string   `json:"created,omitempty"`
+	Modified    string   `json:"modified,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+
+	// file
+	Hashes map[string]string `json:"hashes,omitempty"`
+
+	// malware-analysis
+	Product       string `json:"product,omitempty"`
+	AnalysisEnded string `json:"analysis_ended,omitempty"`
+	Result        string `json:"result,omitempty"`
+	ResultName    string `json:"result_name,omitempty"`
+	SampleRef     string `json:"sample_ref,omitempty"`
+
+	// indicator
+	IndicatorTypes []string `json:"indicator_types,omitempty"`
+	Pattern        string   `json:"pattern,omitempty"`
+	PatternType    string   `json:"pattern_type,omitempty"`
+	ValidFrom      string   `json:"valid_from,omitempty"`
+	ValidUntil     string   `json:"valid_until,omitempty"`
+}
+
+// STIXMalwareResult - map risk level to value of STIX malware-result-ov vocabulary
+func STIXMalwareResult(riskLevel RiskLevel) string {
+	switch riskLevel {
+	case RiskLevelHigh:
+		return "malicious"
+	case RiskLevelMedium, RiskLevelLow:
+		return "suspicious"
+	case RiskLevelNoRisk:
+		return "benign"
+	default:
+		return "unknown"
+	}
+}
+
+// NewSTIXBundle - convert sandbox analysis results and suspicious objects to
+// STIX 2.1 bundle containing file with analyzed sample hashes (for file
+// analysis), malware-analysis with verdict and indicator for each suspicious
+// IP, URL, domain and SHA1. Identifiers are derived from content, so the same
+// results always produce the same objects
+func NewSTIXBundle(result *SandboxAnalysisResultsResponseItem, suspiciousObjects []SandboxSuspiciousObject) *STIXBundle {
+	bundle := &STIXBundle{
+		Type: "bundle",
+		ID:   "bundle--" + uuid.New().String(),
+	}
+	analyzed := stixTime(time.Time(result.AnalysisCompletionDateTime))
+	analysis := &STIXObject{
+		Type:          "malware-analysis",
+		SpecVersion:   STIXSpecVersion,
+		ID:            stixID("malware-analysis", result.ID),
+		Created:       analyzed,
+		Modified:      analyzed,
+		Labels:        result.ThreatTypes,
+		Product:       STIXProduct,
+		AnalysisEnded: analyzed,
+		Result:        STIXMalwareResult(result.RiskLevel),
+	}
+	if len(result.DetectionNames) > 0 {
+		analysis.ResultName = result.DetectionNames[0]
+	}
+	if result.Type != "url" {
+		file := &STIXObject{
+			Type:        "file",
+			SpecVersion: STIXSpecVersion,
+			Hashes:      stixHashes(result.Digest),
+		}
+		file.ID = stixSCOID("file", map[string]any{"hashes": file.Hashes})
+		analysis.SampleRef = file.ID
+		bundle.Objects = append(bundle.Objects, file)
+	}
+	bundle.Objects = append(bundle.Objects, analysis)
+	for _, so := range suspiciousObjects {
+		for _, pattern := range stixPatterns(so) {
+			bundle.Objects = append(bundle.Objects, newSTIXIndicator(so, pattern))
+		}
+	}
+	return bundle
+}
+
+// STIXBundle - return STIX 2.1 bundle for analysis. Nil if analysis has no results
+func (a *Analysis) STIXBundle() *STIXBundle {
+	if a.Result == nil {
+		return nil
+	}
+	return NewSTIXBundle(a.Result, a.SuspiciousObjects)
+}
+
+// JSON - return indented JSON of bundle
+func (b *STIXBundle) JSON() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// newSTIXIndicator - create indicator for suspicious object with given pattern
+func newSTIXIndicator(so SandboxSuspiciousObject, pattern stixPattern) *STIXObject {
+	validFrom := time.Time(so.AnalysisCompletionDateTime)
+	if validFrom.IsZero() {
+		validFrom = time.Now()
+	}
+	indicator := &STIXObject{
+		Type:           "indicator",
+		SpecVersion:    STIXSpecVersion,
+		ID:             stixID("indicator", pattern.pattern),
+		Created:        stixTime(validFrom),
+		Modified:       stixTime(validFrom),
+		Name:           pattern.value,
+		IndicatorTypes: []string{"malicious-activity"},
+		Labels:         []string{"risk-level:" + so.RiskLevel.String()},
+		Pattern:        pattern.pattern,
+		PatternType:    "stix",
+		ValidFrom:      stixTime(validFrom),
+	}
+	if validUntil := time.Time(so.ExpiredDateTime); validUntil.After(validFrom) {
+		indicator.ValidUntil = stixTime(validUntil)
+	}
+	return indicator
+}
+
+type stixPattern struct {
+	value   string
+	pattern string
+}
+
+// stixPatterns - return patterns for each value of suspicious object
+func stixPatterns(so SandboxSuspiciousObject) (patterns []stixPattern) {
+	add := func(value, format string) {
+		if value != "" {
+			patterns = append(patterns, stixPattern{value, fmt.Sprintf(format, stixQuote(value))})
+		}
+	}
+	ipType := "ipv4-addr"
+	if ip := net.ParseIP(so.IP); ip != nil && ip.To4() == nil {
+		ipType = "ipv6-addr"
+	}
+	add(so.IP, "["+ipType+":value = '%s']")
+	add(so.URL, "[url:value = '%s']")
+	add(so.Domain, "[domain-name:value = '%s']")
+	add(strings.ToLower(so.FileSHA1), "[file:hashes.'SHA-1' = '%s']")
+	return
+}
+
+// stixQuote - escape string to be used inside of STIX pattern string literal
+func stixQuote(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
+// stixHashes - return non empty hashes with STIX hash algorithm names
+func stixHashes(digest Digest) map[string]string {
+	hashes := make(map[string]string)
+	for name, value := range map[string]string{"MD5": digest.MD5, "SHA-1": digest.SHA1, "SHA-256": digest.SHA256} {
+		if value != "" {
+			hashes[name] = strings.ToLower(value)
+		}
+	}
+	return hashes
+}
+
+// stixID - return deterministic identifier of object of given type
+func stixID(objectType string, name string) string {
+	return objectType + "--" + uuid.NewSHA1(stixNamespace, []byte(objectType+":"+name)).String()
+}
+
+// stixSCOID - return identifier of cyber observable object derived from JSON
+// of its identifying properties (keys are sorted by json.Marshal)
+func stixSCOID(objectType string, properties map[string]any) string {
+	data, _ := json.Marshal(properties)
+	return objectType + "--" + uuid.NewSHA1(stixNamespace, data).String()
+}
+
+// stixTime - format time as STIX timestamp. Zero time is replaced by current time
+func stixTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Format(stixTimeFormat)
+}
@@ -0,0 +1,87 @@
+package vone
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSTIXBundle(t *testing.T) {
+	analyzed := VisionOneTime(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))
+	expires := VisionOneTime(time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC))
+	result := &SandboxAnalysisResultsResponseItem{
+		ID:                         "012e4eac-9bd9-4e89-95db-77e02f75a6f3",
+		Type:                       "file",
+		Digest:                     Digest{MD5: "AA", SHA1: "bb", SHA256: "cc"},
+		AnalysisCompletionDateTime: analyzed,
+		RiskLevel:                  RiskLevelHigh,
+		DetectionNames:             []string{"Trojan.Test"},
+		ThreatTypes:                []string{"Trojan"},
+	}
+	suspiciousObjects := []SandboxSuspiciousObject{
+		{RiskLevel: RiskLevelHigh, AnalysisCompletionDateTime: analyzed, ExpiredDateTime: expires, IP: "10.0.0.1"},
+		{RiskLevel: RiskLevelMedium, AnalysisCompletionDateTime: analyzed, URL: "http://example.com/it's"},
+		{RiskLevel: RiskLevelLow, AnalysisCompletionDateTime: analyzed, Domain: "example.com", FileSHA1: "DD"},
+		{RiskLevel: RiskLevelLow, AnalysisCompletionDateTime: analyzed, IP: "2001:db8::1"},
+	}
+	bundle := NewSTIXBundle(result, suspiciousObjects)
+	if bundle.Type != "bundle" || !strings.HasPrefix(bundle.ID, "bundle--") {
+		t.Errorf("wrong bundle: %s %s", bundle.Type, bundle.ID)
+	}
+	var types []string
+	for _, object := range bundle.Objects {
+		types = append(types, object.Type)
+	}
+	if strings.Join(types, ",") != "file,malware-analysis,indicator,indicator,indicator,indicator,indicator" {
+		t.Fatalf("wrong objects: %v", types)
+	}
+	file, analysis := bundle.Objects[0], bundle.Objects[1]
+	if file.Hashes["MD5"] != "aa" || file.Hashes["SHA-1"] != "bb" || file.Hashes["SHA-256"] != "cc" || file.Created != "" {
+		t.Errorf("wrong file: %+v", file)
+	}
+	if analysis.Result != "malicious" || analysis.ResultName != "Trojan.Test" || analysis.SampleRef != file.ID ||
+		analysis.AnalysisEnded != "2026-03-01T10:00:00.000Z" {
+		t.Errorf("wrong malware analysis: %+v", analysis)
+	}
+	expected := []struct {
+		pattern    string
+		validUntil string
+	}{
+		{"[ipv4-addr:value = '10.0.0.1']", "2026-04-01T10:00:00.000Z"},
+		{`[url:value = 'http://example.com/it\'s']`, ""},
+		{"[domain-name:value = 'example.com']", ""},
+		{"[file:hashes.'SHA-1' = 'dd']", ""},
+		{"[ipv6-addr:value = '2001:db8::1']", ""},
+	}
+	for i, e := range expected {
+		indicator := bundle.Objects[2+i]
+		if indicator.Pattern != e.pattern || indicator.PatternType != "stix" || indicator.ValidUntil != e.validUntil ||
+			indicator.ValidFrom != "2026-03-01T10:00:00.000Z" {
+			t.Errorf("wrong indicator %d: %+v", i, indicator)
+		}
+	}
+	again := NewSTIXBundle(result, suspiciousObjects)
+	if again.Objects[0].ID != file.ID || again.Objects[2].ID != bundle.Objects[2].ID {
+		t.Errorf("identifiers are not deterministic")
+	}
+	data, err := bundle.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	objects := decoded["objects"].([]any)
+	if objects[0].(map[string]any)["spec_version"] != "2.1" {
+		t.Errorf("wrong JSON: %s", data)
+	}
+
+	result.Type = "url"
+	result.RiskLevel = RiskLevelNoRisk
+	bundle = NewSTIXBundle(result, nil)
+	if len(bundle.Objects) != 1 || bundle.Objects[0].Result != "benign" || bundle.Objects[0].SampleRef != "" {
+		t.Errorf("wrong URL analysis bundle: %+v", bundle.Objects)
+	}
+}
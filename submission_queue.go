@@ -0,0 +1,530 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Sandbox API capabilities
+
+	submission_queue.go - persistent queue of sandbox submissions respecting daily quota
+*/
+
+package vone
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueueState - state of SubmissionQueue item
+type QueueState string
+
+const (
+	QueuePending   QueueState = "pending"   // Waiting for upload
+	QueueUploading QueueState = "uploading" // Claimed by worker for upload
+	QueueSubmitted QueueState = "submitted" // Uploaded and waiting for analysis results
+	QueueAnalyzed  QueueState = "analyzed"  // Analysis results are available
+	QueueFailed    QueueState = "failed"    // Upload or analysis failed
+)
+
+// Kinds of SubmissionQueue items
+const (
+	QueueKindFile = "file"
+	QueueKindURL  = "url"
+)
+
+// QueueItem - file or URL in SubmissionQueue
+type QueueItem struct {
+	ID           string                              // Queue item ID
+	Kind         string                              // QueueKindFile or QueueKindURL
+	Object       string                              // File path or URL
+	State        QueueState                          // Current state
+	SubmissionID string                              // Sandbox submission ID. Set in submitted state
+	Result       *SandboxAnalysisResultsResponseItem // Set in analyzed state
+	Error        string                              // Set in failed state
+	Created      time.Time                           // Time item was added
+	Updated      time.Time                           // Time of last state change
+}
+
+// NextUTCMidnight - return time of next daily submission quota reset
+func NextUTCMidnight(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// SubmissionQueue - durable queue of files and URLs to be analyzed by sandbox.
+// Items are kept in submission_queue table of SQL database, so queue survives
+// restarts: pending items are uploaded and submitted ones are waited for
+// on next Run. Before each upload, remaining submission quota is checked and if
+// it is exhausted, uploads pause until daily quota reset
+type SubmissionQueue struct {
+	// Workers - amount of items processed simultaneously
+	Workers int
+	// Analyzer - used to wait for analysis results
+	Analyzer *Analyzer
+	// IdleInterval - how often Run checks for new items when queue is empty
+	IdleInterval time.Duration
+	// NextReset - return time of next quota reset. Default is NextUTCMidnight
+	NextReset func(now time.Time) time.Time
+	// OnStateChange - if not nil, called after item changed its state
+	OnStateChange func(item QueueItem)
+	// OnQuotaExhausted - if not nil, called when uploads are paused till resetAt
+	OnQuotaExhausted func(resetAt time.Time)
+	// RetryDelay - pause before item failed with transient error (rate limit,
+	// server or network error) is processed again
+	RetryDelay time.Duration
+	// ClaimTimeout - time after which item left in uploading state (for
+	// example by crashed process) can be claimed for upload again
+	ClaimTimeout time.Duration
+
+	vone   *VOne
+	dbPath string
+	db     *sql.DB
+
+	mu        sync.Mutex
+	remaining int // known remaining submissions. Negative if unknown
+	inFlight  map[string]struct{}
+	retryAt   map[string]time.Time // items delayed after transient errors
+}
+
+// NewSubmissionQueue - create submission_queue table if needed and return queue using it
+func NewSubmissionQueue(v1 *VOne, db *sql.DB, dbPath string) (*SubmissionQueue, error) {
+	stmt := `CREATE TABLE IF NOT EXISTS submission_queue (
+		id TEXT NOT NULL PRIMARY KEY,
+		kind TEXT NOT NULL,
+		object TEXT NOT NULL,
+		state TEXT NOT NULL,
+		submission_id TEXT NOT NULL DEFAULT '',
+		result TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		created BIGINT NOT NULL,
+		updated BIGINT NOT NULL
+		)`
+	if _, err := db.Exec(stmt); err != nil {
+		return nil, fmt.Errorf("%s: %w", dbPath, err)
+	}
+	return &SubmissionQueue{
+		Workers:      4,
+		Analyzer:     NewAnalyzer(v1),
+		IdleInterval: 10 * time.Second,
+		NextReset:    NextUTCMidnight,
+		RetryDelay:   time.Minute,
+		ClaimTimeout: time.Hour,
+		vone:         v1,
+		dbPath:       dbPath,
+		db:           db,
+		remaining:    -1,
+		inFlight:     make(map[string]struct{}),
+		retryAt:      make(map[string]time.Time),
+	}, nil
+}
+
+// AddFile - add file to queue and return queue item ID
+func (q *SubmissionQueue) AddFile(ctx context.Context, filePath string) (string, error) {
+	return q.add(ctx, QueueKindFile, filePath)
+}
+
+// AddURL - add URL to queue and return queue item ID
+func (q *SubmissionQueue) AddURL(ctx context.Context, url string) (string, error) {
+	return q.add(ctx, QueueKindURL, url)
+}
+
+func (q *SubmissionQueue) add(ctx context.Context, kind, object string) (string, error) {
+	id := uuid.NewString()
+	now := time.Now().UnixNano()
+	_, err := q.db.ExecContext(ctx, `INSERT INTO submission_queue (id, kind, object, state, created, updated)
+		VALUES ($1, $2, $3, $4, $5, $6)`, id, kind, object, QueuePending, now, now)
+	if err != nil {
+		return "", q.error("Add", err)
+	}
+	return id, nil
+}
+
+// Item - return queue item with given ID
+func (q *SubmissionQueue) Item(ctx context.Context, id string) (*QueueItem, error) {
+	items, err := q.query(ctx, "WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("queue item %s: %w", id, ErrNotFound)
+	}
+	return &items[0], nil
+}
+
+// Items - return items in given state in the order they were added
+func (q *SubmissionQueue) Items(ctx context.Context, state QueueState) ([]QueueItem, error) {
+	return q.query(ctx, "WHERE state = $1 ORDER BY created", state)
+}
+
+// Count - return amount of items in each state
+func (q *SubmissionQueue) Count(ctx context.Context) (map[QueueState]int, error) {
+	rows, err := q.db.QueryContext(ctx, "SELECT state, COUNT(*) FROM submission_queue GROUP BY state")
+	if err != nil {
+		return nil, q.error("Count", err)
+	}
+	defer rows.Close()
+	result := make(map[QueueState]int)
+	for rows.Next() {
+		var state QueueState
+		var count int
+		if err := rows.Scan(&state, &count); err != nil {
+			return nil, q.error("Count", err)
+		}
+		result[state] = count
+	}
+	return result, q.error("Count", rows.Err())
+}
+
+func (q *SubmissionQueue) query(ctx context.Context, where string, args ...any) ([]QueueItem, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT id, kind, object, state, submission_id, result, error,
+		created, updated FROM submission_queue `+where, args...)
+	if err != nil {
+		return nil, q.error("Query", err)
+	}
+	defer rows.Close()
+	var items []QueueItem
+	for rows.Next() {
+		var item QueueItem
+		var result string
+		var created, updated int64
+		err := rows.Scan(&item.ID, &item.Kind, &item.Object, &item.State, &item.SubmissionID,
+			&result, &item.Error, &created, &updated)
+		if err != nil {
+			return nil, q.error("Query Scan", err)
+		}
+		if result != "" {
+			item.Result = new(SandboxAnalysisResultsResponseItem)
+			if err := json.Unmarshal([]byte(result), item.Result); err != nil {
+				return nil, q.error("Query "+item.ID, err)
+			}
+		}
+		item.Created = time.Unix(0, created)
+		item.Updated = time.Unix(0, updated)
+		items = append(items, item)
+	}
+	return items, q.error("Query", rows.Err())
+}
+
+// save - store item state
+func (q *SubmissionQueue) save(ctx context.Context, item *QueueItem) error {
+	var result []byte
+	if item.Result != nil {
+		var err error
+		if result, err = json.Marshal(item.Result); err != nil {
+			return err
+		}
+	}
+	item.Updated = time.Now()
+	_, err := q.db.ExecContext(ctx, `UPDATE submission_queue SET state = $2, submission_id = $3,
+		result = $4, error = $5, updated = $6 WHERE id = $1`,
+		item.ID, item.State, item.SubmissionID, string(result), item.Error, item.Updated.UnixNano())
+	if err != nil {
+		return q.error("Save", err)
+	}
+	if q.OnStateChange != nil {
+		q.OnStateChange(*item)
+	}
+	return nil
+}
+
+// Run - process queue until ctx is cancelled or database error occurs
+func (q *SubmissionQueue) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	jobs := make(chan *QueueItem)
+	var wg sync.WaitGroup
+	for range max(q.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				if err := q.process(ctx, item); err != nil {
+					cancel(err)
+				}
+				q.release(item.ID)
+			}
+		}()
+	}
+	if err := q.dispatch(ctx, jobs); err != nil {
+		cancel(err)
+	}
+	close(jobs)
+	wg.Wait()
+	return context.Cause(ctx)
+}
+
+// dispatch - pass submitted and then pending items to workers until ctx is done
+func (q *SubmissionQueue) dispatch(ctx context.Context, jobs chan<- *QueueItem) error {
+	for {
+		dispatched := 0
+		submitted, err := q.Items(ctx, QueueSubmitted)
+		if err != nil {
+			return err
+		}
+		for i := range submitted {
+			if !q.ready(submitted[i].ID) {
+				continue
+			}
+			if err := q.send(ctx, jobs, &submitted[i]); err != nil {
+				return err
+			}
+			dispatched++
+		}
+		items, err := q.query(ctx, "WHERE state = $1 OR (state = $2 AND updated < $3) ORDER BY created",
+			QueuePending, QueueUploading, time.Now().Add(-q.ClaimTimeout).UnixNano())
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			if !q.ready(items[i].ID) {
+				continue
+			}
+			if err := q.reserve(ctx); err != nil {
+				return err
+			}
+			if err := q.send(ctx, jobs, &items[i]); err != nil {
+				return err
+			}
+			dispatched++
+		}
+		if dispatched > 0 {
+			continue
+		}
+		if err := sleep(ctx, q.IdleInterval, nil); err != nil {
+			return err
+		}
+	}
+}
+
+func (q *SubmissionQueue) send(ctx context.Context, jobs chan<- *QueueItem, item *QueueItem) error {
+	q.mu.Lock()
+	q.inFlight[item.ID] = struct{}{}
+	q.mu.Unlock()
+	select {
+	case jobs <- item:
+		return nil
+	case <-ctx.Done():
+		q.release(item.ID)
+		if item.State != QueueSubmitted {
+			q.refund()
+		}
+		return context.Cause(ctx)
+	}
+}
+
+// ready - check whether item is neither processed nor delayed after transient error
+func (q *SubmissionQueue) ready(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.inFlight[id]; ok {
+		return false
+	}
+	if retryAt, ok := q.retryAt[id]; ok {
+		if time.Now().Before(retryAt) {
+			return false
+		}
+		delete(q.retryAt, id)
+	}
+	return true
+}
+
+func (q *SubmissionQueue) release(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.inFlight, id)
+}
+
+// reserve - take one submission from remaining quota waiting for quota reset if needed
+func (q *SubmissionQueue) reserve(ctx context.Context) error {
+	for {
+		q.mu.Lock()
+		remaining := q.remaining
+		q.mu.Unlock()
+		if remaining < 0 {
+			reserve, err := q.vone.SandboxDailyReserve().Do(ctx)
+			if err != nil {
+				return err
+			}
+			remaining = reserve.SubmissionRemainingCount
+		}
+		if remaining > 0 {
+			q.mu.Lock()
+			q.remaining = remaining - 1
+			q.mu.Unlock()
+			return nil
+		}
+		resetAt := q.NextReset(time.Now())
+		if q.OnQuotaExhausted != nil {
+			q.OnQuotaExhausted(resetAt)
+		}
+		if err := sleep(ctx, time.Until(resetAt), nil); err != nil {
+			return err
+		}
+		q.mu.Lock()
+		q.remaining = -1
+		q.mu.Unlock()
+	}
+}
+
+// refund - return reserved submission of item that was not uploaded
+func (q *SubmissionQueue) refund() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.remaining >= 0 {
+		q.remaining++
+	}
+}
+
+// observeQuota - lower known remaining quota using submission response headers
+func (q *SubmissionQueue) observeQuota(headers *SandboxSubmitFileResponseHeaders) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.remaining < 0 || headers.SubmissionRemainingCount < q.remaining {
+		q.remaining = headers.SubmissionRemainingCount
+	}
+}
+
+// process - upload item if it is pending and wait for analysis results.
+// Only database errors are returned. If ctx is cancelled or transient error
+// occurs, item returns to its previous state
+func (q *SubmissionQueue) process(ctx context.Context, item *QueueItem) error {
+	if item.State != QueueSubmitted {
+		claimed, err := q.claim(ctx, item)
+		if err != nil || !claimed {
+			// item is uploaded by other process since it was listed
+			q.refund()
+			return err
+		}
+		id, err := q.submit(ctx, item)
+		if id == "" {
+			q.refund()
+			return q.fail(ctx, item, err)
+		}
+		// keep submitted task even if upload is not verified
+		item.SubmissionID = id
+		item.State = QueueSubmitted
+		if err := q.save(context.WithoutCancel(ctx), item); err != nil {
+			return err
+		}
+		if err != nil {
+			return q.fail(ctx, item, err)
+		}
+	} else {
+		// item could be analyzed by other worker since it was listed
+		current, err := q.Item(ctx, item.ID)
+		if err != nil || current.State != item.State {
+			return err
+		}
+	}
+	if _, err := q.Analyzer.Wait(ctx, item.SubmissionID); err != nil {
+		return q.fail(ctx, item, err)
+	}
+	result, err := q.vone.SandboxAnalysisResults(item.SubmissionID).Do(ctx)
+	if err != nil {
+		return q.fail(ctx, item, err)
+	}
+	item.Result = result
+	item.State = QueueAnalyzed
+	return q.save(ctx, item)
+}
+
+// fail - mark item failed or delay it if error is transient. Claimed item
+// is returned to pending state in the latter case
+func (q *SubmissionQueue) fail(ctx context.Context, item *QueueItem, err error) error {
+	transient := ctx.Err() != nil || isTransient(err)
+	if transient {
+		q.mu.Lock()
+		q.retryAt[item.ID] = time.Now().Add(q.RetryDelay)
+		q.mu.Unlock()
+		if item.State != QueueUploading {
+			return nil
+		}
+		item.State = QueuePending
+	} else {
+		item.State = QueueFailed
+		item.Error = err.Error()
+	}
+	return q.save(context.WithoutCancel(ctx), item)
+}
+
+// claim - move pending item (or item left in uploading state for longer
+// than ClaimTimeout) to uploading state. Returns false if item is claimed
+// by other worker or process, so each item is uploaded only once even if
+// several processes share the queue
+func (q *SubmissionQueue) claim(ctx context.Context, item *QueueItem) (bool, error) {
+	now := time.Now()
+	result, err := q.db.ExecContext(ctx, `UPDATE submission_queue SET state = $2, updated = $3
+		WHERE id = $1 AND (state = $4 OR (state = $2 AND updated < $5))`,
+		item.ID, QueueUploading, now.UnixNano(), QueuePending, now.Add(-q.ClaimTimeout).UnixNano())
+	if err != nil {
+		return false, q.error("Claim", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, q.error("Claim", err)
+	}
+	if affected == 0 {
+		return false, nil
+	}
+	item.State = QueueUploading
+	item.Updated = now
+	if q.OnStateChange != nil {
+		q.OnStateChange(*item)
+	}
+	return true, nil
+}
+
+// isTransient - check whether request failed due to rate limit, server or
+// network error and can succeed later
+func isTransient(err error) bool {
+	if _, ok := IsRateLimit(err); ok {
+		return true
+	}
+	transient := &RetryPolicy{
+		RetryableStatuses:  DefaultRetryableStatuses,
+		RetryNonIdempotent: true,
+	}
+	return transient.ShouldRetry(methodGet, err)
+}
+
+// submit - upload item and return submission ID. ID is returned along with
+// error if file was accepted but its upload is not verified
+func (q *SubmissionQueue) submit(ctx context.Context, item *QueueItem) (string, error) {
+	if item.Kind == QueueKindURL {
+		response, headers, err := q.vone.SandboxSubmitURLs().AddURL(item.Object).Do(ctx)
+		if err != nil {
+			return "", err
+		}
+		q.observeQuota(headers)
+		if len(response) != 1 {
+			return "", fmt.Errorf("wrong response length: %d", len(response))
+		}
+		if err := response[0].GetError(); err != nil {
+			return "", err
+		}
+		return response[0].Body.ID, nil
+	}
+	submit := q.vone.SandboxSubmitFile()
+	if err := submit.SetFilePath(ctx, item.Object); err != nil {
+		return "", err
+	}
+	response, headers, err := submit.Do(ctx)
+	if headers != nil {
+		q.observeQuota(headers)
+	}
+	if response == nil {
+		return "", err
+	}
+	return response.ID, err
+}
+
+func (q *SubmissionQueue) error(message string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %s: %w", q.dbPath, message, err)
+}
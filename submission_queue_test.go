@@ -0,0 +1,327 @@
+package vone_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mpkondrashin/vone"
+	"github.com/mpkondrashin/vone/vonetest"
+	_ "modernc.org/sqlite"
+)
+
+func TestNextUTCMidnight(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2026, 3, 1, 1, 30, 0, 0, moscow)
+	expected := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if actual := vone.NextUTCMidnight(now); !actual.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func newTestQueue(t *testing.T, s *vonetest.Server, dbPath string) *vone.SubmissionQueue {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	queue, err := vone.NewSubmissionQueue(s.NewVOne(), db, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue.Workers = 2
+	queue.IdleInterval = 10 * time.Millisecond
+	queue.Analyzer = newTestAnalyzer(s.NewVOne(), "")
+	return queue
+}
+
+func addTestFiles(t *testing.T, queue *vone.SubmissionQueue, count int) {
+	folder := t.TempDir()
+	for i := 0; i < count; i++ {
+		path := filepath.Join(folder, fmt.Sprintf("sample%d.exe", i))
+		if err := os.WriteFile(path, []byte(path), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := queue.AddFile(context.Background(), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSubmissionQueueQuota(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.SetDailyReserve(2)
+	queue := newTestQueue(t, s, filepath.Join(t.TempDir(), "queue.sqlite3"))
+	addTestFiles(t, queue, 2)
+	if _, err := queue.AddURL(context.Background(), "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	pauses := 0
+	analyzed := 0
+	queue.NextReset = func(now time.Time) time.Time {
+		return now.Add(50 * time.Millisecond)
+	}
+	queue.OnQuotaExhausted = func(resetAt time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		pauses++
+		s.SetDailyReserve(10)
+	}
+	queue.OnStateChange = func(item vone.QueueItem) {
+		mu.Lock()
+		defer mu.Unlock()
+		if item.State == vone.QueueFailed {
+			t.Errorf("%s failed: %s", item.Object, item.Error)
+		}
+		if item.State == vone.QueueAnalyzed {
+			analyzed++
+			if analyzed == 3 {
+				cancel()
+			}
+		}
+	}
+	if err := queue.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if pauses != 1 {
+		t.Errorf("expected 1 pause, got %d", pauses)
+	}
+	count, err := queue.Count(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count[vone.QueueAnalyzed] != 3 {
+		t.Errorf("expected 3 analyzed items, got %v", count)
+	}
+	items, err := queue.Items(context.Background(), vone.QueueAnalyzed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if item.Result == nil || item.Result.RiskLevel != vone.RiskLevelNoRisk {
+			t.Errorf("unexpected result of %s: %v", item.Object, item.Result)
+		}
+	}
+}
+
+func TestSubmissionQueueRestart(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.AnalysisPolls = 1000
+	dbPath := filepath.Join(t.TempDir(), "queue.sqlite3")
+	queue := newTestQueue(t, s, dbPath)
+	addTestFiles(t, queue, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	queue.OnStateChange = func(item vone.QueueItem) {
+		if item.State == vone.QueueSubmitted {
+			cancel()
+		}
+	}
+	queue.Run(ctx)
+	items, err := queue.Items(context.Background(), vone.QueueSubmitted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 submitted item, got %d", len(items))
+	}
+
+	s.AnalysisPolls = 0
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	restarted := newTestQueue(t, s, dbPath)
+	restarted.OnStateChange = func(item vone.QueueItem) {
+		if item.State == vone.QueueAnalyzed {
+			cancel()
+		}
+	}
+	restarted.Run(ctx)
+	item, err := restarted.Item(context.Background(), items[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.State != vone.QueueAnalyzed || item.SubmissionID != items[0].SubmissionID {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if actual := s.RequestCount("/v3.0/sandbox/files/analyze"); actual != 1 {
+		t.Errorf("expected 1 upload, got %d", actual)
+	}
+}
+
+func TestSubmissionQueueTransientError(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	queue := newTestQueue(t, s, filepath.Join(t.TempDir(), "queue.sqlite3"))
+	queue.RetryDelay = 10 * time.Millisecond
+	addTestFiles(t, queue, 1)
+	s.InjectFault(vonetest.Fault{Path: "/v3.0/sandbox/files/analyze", Status: 503})
+	s.InjectFault(vonetest.Fault{Path: "/v3.0/sandbox/tasks/", Status: 502})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue.OnStateChange = func(item vone.QueueItem) {
+		switch item.State {
+		case vone.QueueFailed:
+			t.Errorf("%s failed: %s", item.Object, item.Error)
+			cancel()
+		case vone.QueueAnalyzed:
+			cancel()
+		}
+	}
+	queue.Run(ctx)
+	count, err := queue.Count(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count[vone.QueueAnalyzed] != 1 {
+		t.Errorf("expected analyzed item, got %v", count)
+	}
+	if actual := s.RequestCount("/v3.0/sandbox/files/analyze"); actual != 2 {
+		t.Errorf("expected 2 uploads, got %d", actual)
+	}
+}
+
+func TestSubmissionQueueDigestMismatch(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	v1 := s.NewVOne()
+	// emulate proxy damaging uploaded content
+	v1.Use(func(next vone.CallFunc) vone.CallFunc {
+		return func(call *vone.Call) error {
+			if call.Operation == "SandboxSubmitFile" {
+				body, err := io.ReadAll(call.Request.Body)
+				if err != nil {
+					return err
+				}
+				body = bytes.ReplaceAll(body, []byte("sample"), []byte("sampla"))
+				call.Request.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			return next(call)
+		}
+	})
+	dbPath := filepath.Join(t.TempDir(), "queue.sqlite3")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	queue, err := vone.NewSubmissionQueue(v1, db, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue.IdleInterval = 10 * time.Millisecond
+	queue.Analyzer = newTestAnalyzer(v1, "")
+	addTestFiles(t, queue, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var states []vone.QueueState
+	queue.OnStateChange = func(item vone.QueueItem) {
+		states = append(states, item.State)
+		if item.State == vone.QueueFailed || item.State == vone.QueueAnalyzed {
+			cancel()
+		}
+	}
+	queue.Run(ctx)
+	items, err := queue.Items(context.Background(), vone.QueueFailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].SubmissionID == "" || !strings.Contains(items[0].Error, vone.ErrDigestMismatch.Error()) {
+		t.Fatalf("expected failed item with submission ID, got %+v (states %v)", items, states)
+	}
+	if fmt.Sprint(states) != "[uploading submitted failed]" {
+		t.Errorf("unexpected states: %v", states)
+	}
+}
+
+func TestSubmissionQueueShared(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	dbPath := filepath.Join(t.TempDir(), "queue.sqlite3")
+	const count = 10
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	analyzed := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		// each queue emulates separate process sharing the same database
+		queue := newTestQueue(t, s, dbPath)
+		queue.Workers = 4
+		if i == 0 {
+			addTestFiles(t, queue, count)
+		}
+		queue.OnStateChange = func(item vone.QueueItem) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch item.State {
+			case vone.QueueFailed:
+				t.Errorf("%s failed: %s", item.Object, item.Error)
+			case vone.QueueAnalyzed:
+				analyzed++
+				if analyzed == count {
+					cancel()
+				}
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			queue.Run(ctx)
+		}()
+	}
+	wg.Wait()
+	if actual := s.RequestCount("/v3.0/sandbox/files/analyze"); actual != count {
+		t.Errorf("expected %d uploads, got %d", count, actual)
+	}
+}
+
+func TestSubmissionQueueRefund(t *testing.T) {
+	s := vonetest.NewServer()
+	defer s.Close()
+	s.SetDailyReserve(1)
+	queue := newTestQueue(t, s, filepath.Join(t.TempDir(), "queue.sqlite3"))
+	queue.NextReset = func(now time.Time) time.Time {
+		return now.Add(10 * time.Millisecond)
+	}
+	addTestFiles(t, queue, 1)
+	s.InjectFault(vonetest.Fault{Path: "/v3.0/sandbox/files/analyze", Status: 400, Count: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pauses := 0
+	queue.OnQuotaExhausted = func(time.Time) {
+		pauses++
+	}
+	queue.OnStateChange = func(item vone.QueueItem) {
+		switch item.State {
+		case vone.QueueFailed:
+			// submission of failed upload is not spent, so the next file is uploaded without pause
+			addTestFiles(t, queue, 1)
+		case vone.QueueAnalyzed:
+			cancel()
+		}
+	}
+	queue.Run(ctx)
+	count, err := queue.Count(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count[vone.QueueFailed] != 1 || count[vone.QueueAnalyzed] != 1 {
+		t.Errorf("unexpected items: %v", count)
+	}
+	if pauses != 0 {
+		t.Errorf("expected no pauses, got %d", pauses)
+	}
+}
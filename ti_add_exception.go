@@ -165,6 +165,10 @@ func (s *tiAddExceptionRequest) url() string {
 	return "/v3.0/threatintel/suspiciousObjectExceptions"
 }
 
+func (s *tiAddExceptionRequest) operation() string {
+	return "AddExceptions"
+}
+
 func (f *tiAddExceptionRequest) requestBody() io.Reader {
 	jsonData, err := json.Marshal(f.request)
 	if err != nil {
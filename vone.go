@@ -93,6 +93,7 @@ type RateLimitError struct {
 	Remaining int
 	Reset     int
 	Err       error
+	request   *http.Request // throttled request. Used by rate limiters to find its endpoint family
 }
 
 func (e *RateLimitError) Error() string {
@@ -168,11 +169,16 @@ func (vot VisionOneTime) MarshalCSV() (string, error) {
 
 // VOne - Vision One API struct
 type VOne struct {
-	Domain      string
-	Token       string
-	client      *http.Client
-	rateLimiter RateLimiter
-	mockup      SandboxMockup
+	Domain          string
+	Token           string
+	client          *http.Client
+	transport       *http.Transport
+	wrappers        []func(http.RoundTripper) http.RoundTripper
+	rateLimiter     RateLimiter
+	retryPolicy     *RetryPolicy
+	middlewares     []Middleware
+	pageMiddlewares []PageMiddleware
+	mockup          SandboxMockup
 }
 
 //transportModifier func(*http.Transport)
@@ -192,12 +198,32 @@ func (v *VOne) SetRateLimiter(rateLimiter RateLimiter) *VOne {
 }
 
 func (v *VOne) AddTransportModifier(transportModifier func(*http.Transport)) {
-	tr, ok := v.client.Transport.(*http.Transport)
-	if !ok || tr == nil {
-		tr = http.DefaultTransport.(*http.Transport).Clone()
+	if v.transport == nil {
+		v.transport = http.DefaultTransport.(*http.Transport).Clone()
 	}
-	transportModifier(tr)
-	v.client.Transport = tr
+	transportModifier(v.transport)
+	v.updateTransport()
+}
+
+// AddTransportWrapper - wrap HTTP transport with custom http.RoundTripper.
+// Wrappers are applied in the order they were added, so the last one added
+// is called first. Transport modifiers are applied to the innermost transport,
+// so wrapper can be called again after each AddTransportModifier call
+func (v *VOne) AddTransportWrapper(wrapper func(http.RoundTripper) http.RoundTripper) *VOne {
+	v.wrappers = append(v.wrappers, wrapper)
+	v.updateTransport()
+	return v
+}
+
+func (v *VOne) updateTransport() {
+	var roundTripper http.RoundTripper = http.DefaultTransport
+	if v.transport != nil {
+		roundTripper = v.transport
+	}
+	for _, wrapper := range v.wrappers {
+		roundTripper = wrapper(roundTripper)
+	}
+	v.client.Transport = roundTripper
 }
 
 func (v *VOne) SetMockup(mockup SandboxMockup) *VOne {
@@ -244,7 +270,18 @@ func (v *VOne) callWithLimiter(ctx context.Context, f vOneRequest) error {
 		}
 	}
 */
+// attemptsKey - context key for counter of attempts of one call
+type attemptsKey struct{}
+
 func (v *VOne) call(ctx context.Context, f vOneRequest) error {
+	ctx = context.WithValue(ctx, attemptsKey{}, new(int))
+	if v.retryPolicy != nil {
+		return v.callWithRetry(ctx, f)
+	}
+	return v.callOnce(ctx, f)
+}
+
+func (v *VOne) callOnce(ctx context.Context, f vOneRequest) error {
 	if v.rateLimiter != nil {
 		return v.callWithLimiter(ctx, f)
 	}
@@ -256,47 +293,35 @@ func parseInt(s string) int {
 	return i
 }
 
-func (v *VOne) callWithoutLimiter(ctx context.Context, f vOneRequest) error {
-	uri := f.uri()
-	if uri == "" {
-		uri = "https://" + v.Domain + f.url()
-	}
-	req, err := http.NewRequestWithContext(ctx, f.method(), uri, f.requestBody())
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+v.Token)
-	if f.requestBody() != nil {
-		req.Header.Set("Content-Type", f.contentType())
-	}
-	f.populateHeaders(req)
-	if f.uri() == "" {
-		f.populateParameters(req.URL)
+// send - perform HTTP exchange. Innermost function of middleware chain
+func (v *VOne) send(call *Call) error {
+	limiter, _ := v.rateLimiter.(EndpointRateLimiter)
+	if limiter != nil {
+		if err := limiter.Wait(call.Request); err != nil {
+			// unblock io.Pipe writer of file upload body
+			if call.Request.Body != nil {
+				call.Request.Body.Close()
+			}
+			return err
+		}
 	}
-	/*
-		fmt.Printf("Method: %v\n", req.Method)
-		fmt.Printf("URL: %v\n", req.URL)
-		fmt.Printf("Proto: %v\n", req.Proto)
-		fmt.Printf("ContentLength: %v\n", req.ContentLength)
-		fmt.Printf("TransferEncoding: %v\n", req.TransferEncoding)
-		fmt.Printf("Host: %v\n", req.Host)
-		fmt.Printf("Form: %v\n", req.Form)
-		fmt.Printf("PostForm: %v\n", req.PostForm)
-		fmt.Printf("MultipartForm: %v\n", req.MultipartForm)
-		fmt.Printf("Trailer: %v\n", req.Trailer)
-		fmt.Printf("RemoteAddr: %v\n", req.RemoteAddr)
-		fmt.Printf("ContentLength: %v\n", req.ContentLength)
-	*/
-	//log.Println(req)
-	resp, err := v.client.Do(req)
+	start := time.Now()
+	resp, err := v.client.Do(call.Request)
+	call.Duration = time.Since(start)
 	if err != nil {
 		return fmt.Errorf("vone: %w", err)
 	}
-	defer resp.Body.Close()
+	call.Response = resp
+	if limiter != nil {
+		limiter.Observe(call.Request, resp)
+	}
 	if GetHTTPCodeRange(resp.StatusCode) != HTTPCodeSuccessRange {
-		vOneErr, err := ErrorFromReader(resp.Body)
+		defer resp.Body.Close()
+		var vOneErr error
+		vOneErr, err = ErrorFromReader(resp.Body)
 		if err != nil {
-			return fmt.Errorf("vone: %w", err)
+			// gateways can respond with body that is not Vision One error JSON
+			vOneErr = fmt.Errorf("decode error response: %w", err)
 		}
 		if resp.StatusCode == 429 {
 			return &RateLimitError{
@@ -306,6 +331,7 @@ func (v *VOne) callWithoutLimiter(ctx context.Context, f vOneRequest) error {
 				Remaining: parseInt(resp.Header.Get("RateLimit-Remaining")),
 				Reset:     parseInt(resp.Header.Get("RateLimit-Reset")),
 				Err:       vOneErr,
+				request:   call.Request,
 			}
 		}
 		return &HTTPError{
@@ -313,16 +339,63 @@ func (v *VOne) callWithoutLimiter(ctx context.Context, f vOneRequest) error {
 			Err:    vOneErr,
 		}
 	}
+	return nil
+}
+
+func (v *VOne) callWithoutLimiter(ctx context.Context, f vOneRequest) error {
+	uri := f.uri()
+	if uri == "" {
+		uri = "https://" + v.Domain + f.url()
+	}
+	body := f.requestBody()
+	req, err := http.NewRequestWithContext(ctx, f.method(), uri, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+v.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", f.contentType())
+	}
+	f.populateHeaders(req)
+	if f.uri() == "" {
+		f.populateParameters(req.URL)
+	}
+	call := &Call{
+		Method:    f.method(),
+		URL:       req.URL.String(),
+		Operation: f.operation(),
+		Attempt:   1,
+		Request:   req,
+	}
+	if attempts, ok := ctx.Value(attemptsKey{}).(*int); ok {
+		*attempts++
+		call.Attempt = *attempts
+	}
+	if err := v.handler()(call); err != nil {
+		if call.Response != nil {
+			call.Response.Body.Close()
+		}
+		return err
+	}
+	if call.Response == nil {
+		if call.Request.Body != nil {
+			call.Request.Body.Close()
+		}
+		return ErrNoResponse
+	}
+	resp := call.Response
 
 	if err := v.PopulateResponseStruct(f.responseHeader(), resp.Header); err != nil {
+		resp.Body.Close()
 		return err
 	}
 
 	if f.responseStruct() == nil {
+		// responseBody is responsible for closing the body
 		f.responseBody(resp.Body)
 		return nil
 	}
-
+	defer resp.Body.Close()
 	return v.DecodeBody(f, resp.Body)
 }
 
@@ -39,6 +39,10 @@ func (f *connectivityRequest) url() string {
 	return "/v3.0/healthcheck/connectivity"
 }
 
+func (f *connectivityRequest) operation() string {
+	return "Connectivity"
+}
+
 func (f *connectivityRequest) responseStruct() any {
 	return &ErrorData{}
 }
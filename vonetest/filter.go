@@ -0,0 +1,373 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Vision One API emulator
+
+	filter.go - TMV1-Filter/TMV1-Query expressions evaluation
+*/
+
+package vonetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// expression - parsed filter expression
+type expression interface {
+	match(item map[string]any) bool
+}
+
+type (
+	orExpression  []expression
+	andExpression []expression
+	notExpression struct {
+		expr expression
+	}
+	comparison struct {
+		field    string
+		operator string
+		value    string
+		isString bool
+	}
+	trueExpression struct{}
+)
+
+func (e orExpression) match(item map[string]any) bool {
+	for _, each := range e {
+		if each.match(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e andExpression) match(item map[string]any) bool {
+	for _, each := range e {
+		if !each.match(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e notExpression) match(item map[string]any) bool {
+	return !e.expr.match(item)
+}
+
+func (trueExpression) match(map[string]any) bool {
+	return true
+}
+
+func (c comparison) match(item map[string]any) bool {
+	actual, ok := lookup(item, c.field)
+	if !ok {
+		return c.operator == "ne"
+	}
+	if list, ok := actual.([]any); ok {
+		if c.operator == "ne" {
+			for _, each := range list {
+				if c.compare(each, "eq") {
+					return false
+				}
+			}
+			return true
+		}
+		for _, each := range list {
+			if c.compare(each, c.operator) {
+				return true
+			}
+		}
+		return false
+	}
+	return c.compare(actual, c.operator)
+}
+
+func (c comparison) compare(actual any, operator string) bool {
+	var result int
+	switch v := actual.(type) {
+	case float64:
+		expected, err := strconv.ParseFloat(c.value, 64)
+		if err != nil {
+			return false
+		}
+		switch {
+		case v < expected:
+			result = -1
+		case v > expected:
+			result = 1
+		}
+	case bool:
+		if strconv.FormatBool(v) != strings.ToLower(c.value) {
+			result = 1
+		}
+	case nil:
+		if c.isString || c.value != "null" {
+			result = 1
+		}
+	default:
+		result = strings.Compare(strings.ToLower(fmt.Sprint(v)), strings.ToLower(c.value))
+	}
+	switch operator {
+	case "eq":
+		return result == 0
+	case "ne":
+		return result != 0
+	case "gt":
+		return result > 0
+	case "ge":
+		return result >= 0
+	case "lt":
+		return result < 0
+	case "le":
+		return result <= 0
+	}
+	return false
+}
+
+// lookup - get value of (possibly dotted) field name. Field names are case insensitive
+func lookup(item map[string]any, field string) (any, bool) {
+	var value any = item
+	for _, name := range strings.Split(field, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		value, ok = m[name]
+		if ok {
+			continue
+		}
+		found := false
+		for key, v := range m {
+			if strings.EqualFold(key, name) {
+				value = v
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+type token struct {
+	text     string
+	isString bool
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		case c == '\'' || c == '"':
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(s) {
+					return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+				}
+				if s[i] == c {
+					if i+1 < len(s) && s[i+1] == c {
+						sb.WriteByte(c)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteByte(s[i])
+				i++
+			}
+			tokens = append(tokens, token{text: sb.String(), isString: true})
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t\r\n()", rune(s[i])) {
+				if s[i] == '\'' || s[i] == '"' {
+					// field:'value' form
+					q := s[i]
+					i++
+					for i < len(s) && s[i] != q {
+						i++
+					}
+				}
+				if i < len(s) {
+					i++
+				}
+			}
+			tokens = append(tokens, token{text: s[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseFilter - parse Vision One filter expression, like
+// "(riskScore ge 50) and not (os eq 'Linux')". Query syntax "field:value" is also
+// accepted as equality check. Empty string matches any item.
+func parseFilter(s string) (expression, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return trueExpression{}, nil
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected \"%s\"", ErrInvalidFilter, p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	t := p.tokens[p.pos]
+	return !t.isString && strings.EqualFold(t.text, keyword)
+}
+
+func (p *parser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("%w: unexpected end", ErrInvalidFilter)
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *parser) parseOr() (expression, error) {
+	expr, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	result := orExpression{expr}
+	for p.peekKeyword("or") {
+		p.pos++
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expr)
+	}
+	if len(result) == 1 {
+		return result[0], nil
+	}
+	return result, nil
+}
+
+func (p *parser) parseAnd() (expression, error) {
+	expr, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	result := andExpression{expr}
+	for p.peekKeyword("and") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expr)
+	}
+	if len(result) == 1 {
+		return result[0], nil
+	}
+	return result, nil
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{expr}, nil
+	}
+	if p.peekKeyword("(") {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekKeyword(")") {
+			return nil, fmt.Errorf("%w: missing \")\"", ErrInvalidFilter)
+		}
+		p.pos++
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expression, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if field.isString {
+		return nil, fmt.Errorf("%w: field name expected, got '%s'", ErrInvalidFilter, field.text)
+	}
+	if name, value, found := strings.Cut(field.text, ":"); found {
+		return comparison{
+			field:    name,
+			operator: "eq",
+			value:    strings.Trim(value, `'"`),
+			isString: true,
+		}, nil
+	}
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(operator.text)
+	switch op {
+	case "eq", "ne", "gt", "ge", "lt", "le":
+	default:
+		return nil, fmt.Errorf("%w: unknown operator \"%s\"", ErrInvalidFilter, operator.text)
+	}
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	return comparison{
+		field:    field.text,
+		operator: op,
+		value:    value.text,
+		isString: value.isString,
+	}, nil
+}
+
+// toMap - convert item to generic JSON representation for filter evaluation
+func toMap(item any) (map[string]any, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,509 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Vision One API emulator
+
+	sandbox.go - sandbox API emulation
+*/
+
+package vonetest
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mpkondrashin/vone"
+)
+
+// MaxURLsPerRequest - maximum amount of URLs accepted by single submit URLs request
+const MaxURLsPerRequest = vone.MaxURLsPerRequest
+
+// Verdict - analysis outcome emulator reports for submitted object
+type Verdict struct {
+	RiskLevel         vone.RiskLevel
+	DetectionNames    []string
+	ThreatTypes       []string
+	TrueFileType      string
+	SuspiciousObjects []vone.SandboxSuspiciousObject
+	// DroppedFiles - files by name included into investigation package as dropped by sample
+	DroppedFiles map[string][]byte
+	// Error - if not nil, submission fails with this error
+	Error *vone.Error
+}
+
+// NoRiskVerdict - verdict returned for objects without explicitly set verdict
+var NoRiskVerdict = Verdict{
+	RiskLevel: vone.RiskLevelNoRisk,
+}
+
+type task struct {
+	status            vone.SandboxSubmissionStatusResponse
+	result            vone.SandboxAnalysisResultsResponseItem
+	suspiciousObjects []vone.SandboxSuspiciousObject
+	polls             int
+	fields            map[string]string
+	dropped           map[string][]byte
+}
+
+// SetFileVerdict - set verdict for file with given SHA1
+func (s *Server) SetFileVerdict(sha1 string, verdict Verdict) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fileVerdicts[strings.ToLower(sha1)] = verdict
+}
+
+// SetURLVerdict - set verdict for URL
+func (s *Server) SetURLVerdict(url string, verdict Verdict) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.urlVerdicts[url] = verdict
+}
+
+// SubmissionFields - return decoded multipart form fields (like archivePassword)
+// of file submission with given ID
+func (s *Server) SubmissionFields(id string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return nil
+	}
+	return maps.Clone(t.fields)
+}
+
+// SetDailyReserve - set daily submissions quota and reset today submissions count
+func (s *Server) SetDailyReserve(reserve int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reserve = vone.SandboxDailyReserveResponse{
+		SubmissionReserveCount:   reserve,
+		SubmissionRemainingCount: reserve,
+	}
+}
+
+// AddAnalysisResult - seed already finished analysis. Result ID is used as task ID
+func (s *Server) AddAnalysisResult(result vone.SandboxAnalysisResultsResponseItem, suspiciousObjects ...vone.SandboxSuspiciousObject) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if result.ID == "" {
+		result.ID = uuid.New().String()
+	}
+	action := vone.ActionAnalyzeFile
+	if result.Type == "url" {
+		action = vone.ActionAnalyzeUrl
+	}
+	s.addTask(&task{
+		status: vone.SandboxSubmissionStatusResponse{
+			ID:                 result.ID,
+			Action:             action,
+			Status:             vone.StatusSucceeded,
+			CreatedDateTime:    result.AnalysisCompletionDateTime,
+			LastActionDateTime: result.AnalysisCompletionDateTime,
+			Digest:             result.Digest,
+			Arguments:          result.Arguments,
+		},
+		result:            result,
+		suspiciousObjects: suspiciousObjects,
+	})
+}
+
+// addTask - should be called with mu locked
+func (s *Server) addTask(t *task) {
+	s.tasks[t.status.ID] = t
+	s.taskOrder = append(s.taskOrder, t.status.ID)
+}
+
+func (s *Server) sandboxRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /v3.0/sandbox/files/analyze", s.submitFile)
+	mux.HandleFunc("POST /v3.0/sandbox/urls/analyze", s.submitURLs)
+	mux.HandleFunc("GET /v3.0/sandbox/submissionUsage", s.submissionUsage)
+	mux.HandleFunc("GET /v3.0/sandbox/tasks", s.listTasks)
+	mux.HandleFunc("GET /v3.0/sandbox/tasks/{id}", s.taskStatus)
+	mux.HandleFunc("GET /v3.0/sandbox/analysisResults", s.listAnalysisResults)
+	mux.HandleFunc("GET /v3.0/sandbox/analysisResults/{id}", s.analysisResult)
+	mux.HandleFunc("GET /v3.0/sandbox/analysisResults/{id}/suspiciousObjects", s.suspiciousObjects)
+	mux.HandleFunc("GET /v3.0/sandbox/analysisResults/{id}/report", s.report)
+	mux.HandleFunc("GET /v3.0/sandbox/analysisResults/{id}/investigationPackage", s.investigationPackage)
+}
+
+// newTask - should be called with mu locked
+func (s *Server) newTask(action vone.Action, digest vone.Digest, arguments string, verdict Verdict) *task {
+	now := vone.VisionOneTime(time.Now().UTC().Truncate(time.Second))
+	id := uuid.New().String()
+	objectType := "file"
+	if action == vone.ActionAnalyzeUrl {
+		objectType = "url"
+	}
+	t := &task{
+		status: vone.SandboxSubmissionStatusResponse{
+			ID:                 id,
+			Action:             action,
+			Status:             vone.StatusRunning,
+			CreatedDateTime:    now,
+			LastActionDateTime: now,
+			Digest:             digest,
+			Arguments:          arguments,
+		},
+		result: vone.SandboxAnalysisResultsResponseItem{
+			ID:             id,
+			Type:           objectType,
+			Digest:         digest,
+			Arguments:      arguments,
+			RiskLevel:      verdict.RiskLevel,
+			DetectionNames: verdict.DetectionNames,
+			ThreatTypes:    verdict.ThreatTypes,
+			TrueFileType:   verdict.TrueFileType,
+		},
+		suspiciousObjects: verdict.SuspiciousObjects,
+		dropped:           verdict.DroppedFiles,
+	}
+	if verdict.Error != nil {
+		t.status.Error = *verdict.Error
+	}
+	s.addTask(t)
+	s.reserve.SubmissionRemainingCount--
+	s.reserve.SubmissionCount++
+	if action == vone.ActionAnalyzeUrl {
+		s.reserve.SubmissionCountDetail.URLCount++
+	} else {
+		s.reserve.SubmissionCountDetail.FileCount++
+	}
+	return t
+}
+
+// setQuotaHeaders - should be called with mu locked
+func (s *Server) setQuotaHeaders(w http.ResponseWriter) {
+	w.Header().Set("TMV1-Submission-Reserve-Count", strconv.Itoa(s.reserve.SubmissionReserveCount))
+	w.Header().Set("TMV1-Submission-Remaining-Count", strconv.Itoa(s.reserve.SubmissionRemainingCount))
+	w.Header().Set("TMV1-Submission-Count", strconv.Itoa(s.reserve.SubmissionCount))
+	w.Header().Set("TMV1-Submission-Exemption-Count", strconv.Itoa(s.reserve.SubmissionExemptionCount))
+}
+
+func (s *Server) operationLocation(r *http.Request, id string) string {
+	return "https://" + r.Host + "/v3.0/sandbox/tasks/" + id
+}
+
+func (s *Server) submitFile(w http.ResponseWriter, r *http.Request) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	var digest vone.Digest
+	fields := make(map[string]string)
+	found := false
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, err.Error())
+			return
+		}
+		if part.FormName() != "file" {
+			value, err := io.ReadAll(part)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, err.Error())
+				return
+			}
+			decoded, err := base64.StdEncoding.DecodeString(string(value))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest,
+					fmt.Sprintf("%s is not base64 encoded", part.FormName()))
+				return
+			}
+			fields[part.FormName()] = string(decoded)
+			continue
+		}
+		md5Hash, sha1Hash, sha256Hash := md5.New(), sha1.New(), sha256.New()
+		if _, err := io.Copy(io.MultiWriter(md5Hash, sha1Hash, sha256Hash), part); err != nil {
+			writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, err.Error())
+			return
+		}
+		digest = vone.Digest{
+			MD5:    hex.EncodeToString(md5Hash.Sum(nil)),
+			SHA1:   hex.EncodeToString(sha1Hash.Sum(nil)),
+			SHA256: hex.EncodeToString(sha256Hash.Sum(nil)),
+		}
+		found = true
+	}
+	if !found {
+		writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, "File is missing")
+		return
+	}
+	arguments := fields["arguments"]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reserve.SubmissionRemainingCount <= 0 {
+		writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, "Daily submission quota exceeded")
+		return
+	}
+	verdict, ok := s.fileVerdicts[digest.SHA1]
+	if !ok {
+		verdict = NoRiskVerdict
+	}
+	t := s.newTask(vone.ActionAnalyzeFile, digest, arguments, verdict)
+	t.fields = fields
+	s.setQuotaHeaders(w)
+	w.Header().Set("Operation-Location", s.operationLocation(r, t.status.ID))
+	writeJSON(w, http.StatusAccepted, vone.SandboxSubmitFileResponse{
+		ID:        t.status.ID,
+		Digest:    digest,
+		Arguments: arguments,
+	})
+}
+
+func (s *Server) submitURLs(w http.ResponseWriter, r *http.Request) {
+	var request vone.SubmitURLsToSandboxRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	if len(request) == 0 || len(request) > MaxURLsPerRequest {
+		writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest,
+			fmt.Sprintf("Amount of URLs should be from 1 to %d", MaxURLsPerRequest))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var response vone.SandboxSubmitURLsToSandboxResponse
+	for _, each := range request {
+		var item vone.SubmitURLsToSandboxStruct
+		item.Body.URL = each.URL
+		if s.reserve.SubmissionRemainingCount <= 0 {
+			item.Status = http.StatusBadRequest
+			item.Body.Error = vone.Innererror{Code: "BadRequest"}
+			response = append(response, item)
+			continue
+		}
+		verdict, ok := s.urlVerdicts[each.URL]
+		if !ok {
+			verdict = NoRiskVerdict
+		}
+		sum := sha1.Sum([]byte(each.URL))
+		digest := vone.Digest{SHA1: hex.EncodeToString(sum[:])}
+		t := s.newTask(vone.ActionAnalyzeUrl, digest, "", verdict)
+		item.Status = http.StatusAccepted
+		item.Headers = append(item.Headers, struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		}{
+			Name:  "Operation-Location",
+			Value: s.operationLocation(r, t.status.ID),
+		})
+		item.Body.ID = t.status.ID
+		item.Body.Digest = digest
+		response = append(response, item)
+	}
+	s.setQuotaHeaders(w)
+	writeJSON(w, http.StatusMultiStatus, response)
+}
+
+func (s *Server) submissionUsage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.reserve)
+}
+
+// lookupTask - should be called with mu locked
+func (s *Server) lookupTask(w http.ResponseWriter, r *http.Request) (*task, bool) {
+	id := r.PathValue("id")
+	t, ok := s.tasks[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, vone.ErrorCodeNotFound, fmt.Sprintf("Task %s not found", id))
+		return nil, false
+	}
+	return t, true
+}
+
+// lookupResult - should be called with mu locked
+func (s *Server) lookupResult(w http.ResponseWriter, r *http.Request) (*task, bool) {
+	t, ok := s.lookupTask(w, r)
+	if !ok {
+		return nil, false
+	}
+	if t.status.Status != vone.StatusSucceeded {
+		writeError(w, http.StatusNotFound, vone.ErrorCodeNotFound,
+			fmt.Sprintf("Analysis result %s not found", t.status.ID))
+		return nil, false
+	}
+	return t, true
+}
+
+// advance - should be called with mu locked
+func (s *Server) advance(t *task) {
+	if t.status.Status != vone.StatusRunning {
+		return
+	}
+	t.polls++
+	if t.polls <= s.AnalysisPolls {
+		return
+	}
+	now := vone.VisionOneTime(time.Now().UTC().Truncate(time.Second))
+	t.status.LastActionDateTime = now
+	if t.status.Error.Code != vone.ErrorCodeOK {
+		t.status.Status = vone.StatusFailed
+		return
+	}
+	t.status.Status = vone.StatusSucceeded
+	t.status.ResourceLocation = "/v3.0/sandbox/analysisResults/" + t.status.ID
+	t.result.AnalysisCompletionDateTime = now
+}
+
+func (s *Server) taskStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookupTask(w, r)
+	if !ok {
+		return
+	}
+	s.advance(t)
+	status := t.status
+	if status.ResourceLocation != "" {
+		status.ResourceLocation = "https://" + r.Host + status.ResourceLocation
+	}
+	if status.Status == vone.StatusRunning && s.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(s.RetryAfter))
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	var items []vone.SandboxSubmissionStatusResponse
+	for _, id := range s.taskOrder {
+		items = append(items, s.tasks[id].status)
+	}
+	s.mu.Unlock()
+	p, err := paginate(s, r, items, r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.SandboxSubmissionsResponse{
+		Items:    p.Items,
+		NextLink: p.NextLink,
+	})
+}
+
+func (s *Server) listAnalysisResults(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	var items []vone.SandboxAnalysisResultsResponseItem
+	for _, id := range s.taskOrder {
+		t := s.tasks[id]
+		if t.status.Status == vone.StatusSucceeded {
+			items = append(items, t.result)
+		}
+	}
+	s.mu.Unlock()
+	p, err := paginate(s, r, items, r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.SandboxListAnalysisResultResponse{
+		Items:    p.Items,
+		NextLink: p.NextLink,
+	})
+}
+
+func (s *Server) analysisResult(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookupResult(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, t.result)
+}
+
+func (s *Server) suspiciousObjects(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookupResult(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.SandboxSuspiciousObjectsResponse{
+		Items: t.suspiciousObjects,
+	})
+}
+
+func (s *Server) report(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookupResult(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "%%PDF-1.4\n%% vonetest report %s: %v\n%%%%EOF\n", t.status.ID, t.result.RiskLevel)
+}
+
+// investigationPackage - serve zip with layout described by vone.DroppedFolders
+func (s *Server) investigationPackage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	t, ok := s.lookupResult(w, r)
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	result := t.result
+	dropped := t.dropped
+	s.mu.Unlock()
+	report, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, vone.ErrorCodeInternalServerError, err.Error())
+		return
+	}
+	files := map[string][]byte{
+		"report.json":              report,
+		"screenshot/1.png":         []byte("\x89PNG\r\n\x1a\n"),
+		"network/traffic.pcap":     {0xd4, 0xc3, 0xb2, 0xa1, 2, 0, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0, 0, 1, 0, 0, 0},
+		"analysis/environment.txt": []byte("vonetest\n"),
+	}
+	for name, data := range dropped {
+		files["dropped/"+name] = data
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		var f io.Writer
+		f, err = zw.Create(name)
+		if err == nil {
+			_, err = f.Write(files[name])
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, vone.ErrorCodeInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
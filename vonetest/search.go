@@ -0,0 +1,179 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Vision One API emulator
+
+	search.go - OAT, endpoints, search, ASRM and threat intelligence APIs emulation
+*/
+
+package vonetest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mpkondrashin/vone"
+)
+
+// AddOATDetections - seed observed attack techniques detections
+func (s *Server) AddOATDetections(items ...vone.ObservedAttackTechniquesEventsItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.oatDetections = append(s.oatDetections, items...)
+}
+
+// AddEndpoints - seed endpoint security endpoints list
+func (s *Server) AddEndpoints(items ...vone.EndpointListItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints = append(s.endpoints, items...)
+}
+
+// AddEndpointData - seed endpoint data search items
+func (s *Server) AddEndpointData(items ...vone.SearchEndPointDataResponseItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpointData = append(s.endpointData, items...)
+}
+
+// AddEndpointActivities - seed endpoint activity search items
+func (s *Server) AddEndpointActivities(items ...vone.GetEndpointActivityResponseItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpointEvents = append(s.endpointEvents, items...)
+}
+
+// AddNetworkActivities - seed network activity search items
+func (s *Server) AddNetworkActivities(items ...vone.GetNetworkActivityResponseItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.networkEvents = append(s.networkEvents, items...)
+}
+
+// AddMobileActivities - seed mobile activity search items
+func (s *Server) AddMobileActivities(items ...vone.GetMobileActivityResponseItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mobileEvents = append(s.mobileEvents, items...)
+}
+
+// AddHighRiskDevices - seed ASRM high risk devices
+func (s *Server) AddHighRiskDevices(items ...vone.HighRiskDevicesItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices = append(s.devices, items...)
+}
+
+// Exceptions - return suspicious object exceptions added so far
+func (s *Server) Exceptions() []vone.TIException {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]vone.TIException(nil), s.exceptions...)
+}
+
+func (s *Server) listOATDetections(w http.ResponseWriter, r *http.Request) {
+	p, ok := paginateHeader(s, w, r, &s.oatDetections, "TMV1-Filter")
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.ObservedAttackTechniquesEventsResponse{
+		TotalCount: p.TotalCount,
+		Count:      len(p.Items),
+		Items:      p.Items,
+		NextLink:   p.NextLink,
+	})
+}
+
+func (s *Server) listEndpoints(w http.ResponseWriter, r *http.Request) {
+	p, ok := paginateHeader(s, w, r, &s.endpoints, "TMV1-Filter")
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.EndpointListResponse{
+		TotalCount: p.TotalCount,
+		Count:      len(p.Items),
+		Items:      p.Items,
+		NextLink:   p.NextLink,
+	})
+}
+
+func (s *Server) searchEndpointData(w http.ResponseWriter, r *http.Request) {
+	p, ok := paginateHeader(s, w, r, &s.endpointData, "TMV1-Query")
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.SearchEndPointDataResponse{
+		Items:    p.Items,
+		NextLink: p.NextLink,
+	})
+}
+
+func (s *Server) searchEndpointActivities(w http.ResponseWriter, r *http.Request) {
+	p, ok := paginateHeader(s, w, r, &s.endpointEvents, "TMV1-Query")
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.GetEndpointActivityResponse{
+		Items:        p.Items,
+		NextLink:     p.NextLink,
+		ProgressRate: 100,
+	})
+}
+
+func (s *Server) searchNetworkActivities(w http.ResponseWriter, r *http.Request) {
+	p, ok := paginateHeader(s, w, r, &s.networkEvents, "TMV1-Query")
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.GetNetworkActivityResponse{
+		Items:        p.Items,
+		NextLink:     p.NextLink,
+		ProgressRate: 100,
+	})
+}
+
+func (s *Server) searchMobileActivities(w http.ResponseWriter, r *http.Request) {
+	p, ok := paginateHeader(s, w, r, &s.mobileEvents, "TMV1-Query")
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.GetMobileActivityResponse{
+		Items:        p.Items,
+		NextLink:     p.NextLink,
+		ProgressRate: 100,
+	})
+}
+
+func (s *Server) listHighRiskDevices(w http.ResponseWriter, r *http.Request) {
+	p, ok := paginateHeader(s, w, r, &s.devices, "TMV1-Filter")
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.HighRiskDevicesResponse{
+		TotalCount: p.TotalCount,
+		Count:      len(p.Items),
+		Items:      p.Items,
+		NextLink:   p.NextLink,
+	})
+}
+
+type exceptionStatus struct {
+	Status int `json:"status"`
+}
+
+func (s *Server) addExceptions(w http.ResponseWriter, r *http.Request) {
+	var request vone.TIAddException
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	response := make([]exceptionStatus, len(request))
+	for i := range request {
+		s.exceptions = append(s.exceptions, request[i])
+		response[i].Status = http.StatusNoContent
+	}
+	writeJSON(w, http.StatusMultiStatus, response)
+}
@@ -0,0 +1,396 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Vision One API emulator
+
+	server.go - local HTTP Vision One API emulator built on httptest
+*/
+
+// Package vonetest provides local Vision One API emulator to be used in tests.
+//
+// Emulator is a real HTTPS server, so requests made by vone SDK go through
+// the same code path as requests to Vision One itself:
+//
+//	s := vonetest.NewServer()
+//	defer s.Close()
+//	v1 := s.NewVOne()
+//	s.AddAlerts(alerts...)
+//	for alert, err := range v1.WorkbenchListAlerts().Paginator().Range(ctx) {
+//	...
+package vonetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mpkondrashin/vone"
+)
+
+const (
+	// DefaultToken - token expected by emulator if Token field is not changed
+	DefaultToken = "vonetest-token"
+	// DefaultPageSize - amount of items returned per page if "top" parameter is not provided
+	DefaultPageSize = 100
+	// DefaultDailyReserve - default daily submissions quota
+	DefaultDailyReserve = 10000
+)
+
+var ErrInvalidParameter = errors.New("invalid parameter")
+
+// Request - request received by emulator
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+}
+
+// Fault - error emulator should return instead of processing request
+type Fault struct {
+	Method string // Method to match. Empty string matches any method
+	Path   string // Path prefix to match. Empty string matches any path
+	Status int    // HTTP status to return
+	Count  int    // Amount of requests to fail. Zero means one
+	Reset  int    // RateLimit-Reset and Retry-After header value in seconds (for 429 status)
+}
+
+// Server - Vision One API emulator
+type Server struct {
+	// Token - token that should be provided in Authorization header
+	Token string
+	// PageSize - amount of items per page if "top" parameter is not provided
+	PageSize int
+	// AnalysisPolls - amount of submission status requests returning "running" before analysis is done
+	AnalysisPolls int
+	// RetryAfter - Retry-After header value in seconds returned along with "running" submission status
+	RetryAfter int
+	// RateLimit - amount of requests allowed for each API group (like sandbox or workbench)
+	// during RateLimitWindow. Zero disables rate limiting and RateLimit-* headers
+	RateLimit int
+	// RateLimitWindow - rate limit window in seconds
+	RateLimitWindow int
+
+	server *httptest.Server
+	mu     sync.Mutex
+
+	requests   []Request
+	faults     []*Fault
+	rateWindow map[string]*rateWindow
+	throttled  int
+
+	tasks          map[string]*task
+	taskOrder      []string
+	fileVerdicts   map[string]Verdict
+	urlVerdicts    map[string]Verdict
+	reserve        vone.SandboxDailyReserveResponse
+	alerts         []vone.WorkbenchAlert
+	oatDetections  []vone.ObservedAttackTechniquesEventsItem
+	endpoints      []vone.EndpointListItem
+	endpointData   []vone.SearchEndPointDataResponseItem
+	endpointEvents []vone.GetEndpointActivityResponseItem
+	networkEvents  []vone.GetNetworkActivityResponseItem
+	mobileEvents   []vone.GetMobileActivityResponseItem
+	devices        []vone.HighRiskDevicesItem
+	exceptions     []vone.TIException
+}
+
+// NewServer - start new emulator. Close should be called when it is not needed anymore
+func NewServer() *Server {
+	s := &Server{
+		Token:           DefaultToken,
+		PageSize:        DefaultPageSize,
+		AnalysisPolls:   1,
+		RateLimitWindow: 60,
+		rateWindow:      make(map[string]*rateWindow),
+		tasks:           make(map[string]*task),
+		fileVerdicts:    make(map[string]Verdict),
+		urlVerdicts:     make(map[string]Verdict),
+	}
+	s.reserve.SubmissionReserveCount = DefaultDailyReserve
+	s.reserve.SubmissionRemainingCount = DefaultDailyReserve
+	s.server = httptest.NewTLSServer(s.handler())
+	return s
+}
+
+// Close - shutdown emulator
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL - base URL of emulator
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Domain - emulator address to be used as VOne Domain
+func (s *Server) Domain() string {
+	u, _ := url.Parse(s.server.URL)
+	return u.Host
+}
+
+// TransportModifier - modifier for VOne.AddTransportModifier to trust emulator certificate
+func (s *Server) TransportModifier() func(*http.Transport) {
+	tlsConfig := s.server.Client().Transport.(*http.Transport).TLSClientConfig
+	return func(tr *http.Transport) {
+		tr.TLSClientConfig = tlsConfig.Clone()
+	}
+}
+
+// NewVOne - return VOne configured to use emulator
+func (s *Server) NewVOne() *vone.VOne {
+	v1 := vone.NewVOne(s.Domain(), s.Token)
+	v1.AddTransportModifier(s.TransportModifier())
+	return v1
+}
+
+// InjectFault - add fault to be returned for matching requests. Faults are
+// matched in the order they were added
+func (s *Server) InjectFault(fault Fault) {
+	if fault.Count == 0 {
+		fault.Count = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// Requests - return all requests received by emulator
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestCount - return amount of requests with given path prefix
+func (s *Server) RequestCount(pathPrefix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, r := range s.requests {
+		if strings.HasPrefix(r.Path, pathPrefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v3.0/healthcheck/connectivity", s.connectivity)
+	s.sandboxRoutes(mux)
+	s.workbenchRoutes(mux)
+	mux.HandleFunc("GET /v3.0/oat/detections", s.listOATDetections)
+	mux.HandleFunc("GET /v3.0/endpointSecurity/endpoints", s.listEndpoints)
+	mux.HandleFunc("GET /v3.0/eiqs/endpoints", s.searchEndpointData)
+	mux.HandleFunc("GET /v3.0/search/endpointActivities", s.searchEndpointActivities)
+	mux.HandleFunc("GET /v3.0/search/networkActivities", s.searchNetworkActivities)
+	mux.HandleFunc("GET /v3.0/search/mobileActivities", s.searchMobileActivities)
+	mux.HandleFunc("GET /v3.0/asrm/highRiskDevices", s.listHighRiskDevices)
+	mux.HandleFunc("POST /v3.0/threatintel/suspiciousObjectExceptions", s.addExceptions)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, vone.ErrorCodeNotFound, "Resource not found")
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Header: r.Header.Clone(),
+		})
+		fault := s.matchFault(r)
+		limited := s.checkRateLimit(w, r)
+		s.mu.Unlock()
+		if fault != nil {
+			writeFault(w, fault)
+			return
+		}
+		if limited {
+			writeError(w, http.StatusTooManyRequests, vone.ErrorCodeTooManyRequests, "Too many requests")
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+s.Token {
+			writeError(w, http.StatusUnauthorized, vone.ErrorCodeInvalidCredentials, "Invalid token")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// matchFault - should be called with mu locked
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, fault := range s.faults {
+		if fault.Method != "" && fault.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, fault.Path) {
+			continue
+		}
+		result := *fault
+		fault.Count--
+		if fault.Count <= 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+		return &result
+	}
+	return nil
+}
+
+// rateWindow - requests counter of one API group
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// checkRateLimit - set RateLimit-* headers and check whether limit is surpassed.
+// Should be called with mu locked
+func (s *Server) checkRateLimit(w http.ResponseWriter, r *http.Request) bool {
+	if s.RateLimit <= 0 {
+		return false
+	}
+	family := vone.EndpointFamily(r)
+	window := time.Duration(s.RateLimitWindow) * time.Second
+	now := time.Now()
+	rw, ok := s.rateWindow[family]
+	if !ok || now.Sub(rw.start) >= window {
+		rw = &rateWindow{start: now}
+		s.rateWindow[family] = rw
+	}
+	limited := rw.count >= s.RateLimit
+	if limited {
+		s.throttled++
+	} else {
+		rw.count++
+	}
+	reset := strconv.Itoa(int(math.Ceil(rw.start.Add(window).Sub(now).Seconds())))
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(s.RateLimit))
+	w.Header().Set("RateLimit-Window", strconv.Itoa(s.RateLimitWindow))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(s.RateLimit-rw.count))
+	w.Header().Set("RateLimit-Reset", reset)
+	if limited {
+		w.Header().Set("Retry-After", reset)
+	}
+	return limited
+}
+
+// Throttled - return amount of requests rejected by emulator because of RateLimit
+func (s *Server) Throttled() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.throttled
+}
+
+func (s *Server) connectivity(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, vone.CheckConnectionResponse{Status: "available"})
+}
+
+func writeFault(w http.ResponseWriter, fault *Fault) {
+	code := vone.ErrorCodeInternalServerError
+	message := "Injected fault"
+	if fault.Status == http.StatusTooManyRequests {
+		reset := strconv.Itoa(fault.Reset)
+		w.Header().Set("RateLimit-Limit", "0")
+		w.Header().Set("RateLimit-Window", "60")
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", reset)
+		w.Header().Set("Retry-After", reset)
+		code = vone.ErrorCodeTooManyRequests
+		message = "Too many requests"
+	}
+	writeError(w, fault.Status, code, message)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, code vone.ErrorCode, message string) {
+	writeJSON(w, status, vone.ErrorData{
+		Error: vone.Error{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
+
+// page - one page of filtered items
+type page[T any] struct {
+	Items      []T
+	TotalCount int
+	NextLink   string
+}
+
+// paginate - filter items and cut page according to "top" and "skipToken" parameters
+func paginate[T any](s *Server, r *http.Request, items []T, filter string) (*page[T], error) {
+	expr, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	var filtered []T
+	for _, item := range items {
+		m, err := toMap(item)
+		if err != nil {
+			return nil, err
+		}
+		if expr.match(m) {
+			filtered = append(filtered, item)
+		}
+	}
+	query := r.URL.Query()
+	top := s.PageSize
+	if t := query.Get("top"); t != "" {
+		top, err = strconv.Atoi(t)
+		if err != nil || top <= 0 {
+			return nil, fmt.Errorf("top: %s: %w", t, ErrInvalidParameter)
+		}
+	}
+	skip := 0
+	if t := query.Get("skipToken"); t != "" {
+		skip, err = strconv.Atoi(t)
+		if err != nil || skip < 0 {
+			return nil, fmt.Errorf("skipToken: %s: %w", t, ErrInvalidParameter)
+		}
+	}
+	result := &page[T]{
+		TotalCount: len(filtered),
+	}
+	if skip > len(filtered) {
+		skip = len(filtered)
+	}
+	end := min(skip+top, len(filtered))
+	result.Items = filtered[skip:end]
+	if end < len(filtered) {
+		query.Set("skipToken", strconv.Itoa(end))
+		next := url.URL{
+			Scheme:   "https",
+			Host:     r.Host,
+			Path:     r.URL.Path,
+			RawQuery: query.Encode(),
+		}
+		result.NextLink = next.String()
+	}
+	return result, nil
+}
+
+// paginateHeader - paginate items using TMV1-Filter header and write error if any
+func paginateHeader[T any](s *Server, w http.ResponseWriter, r *http.Request, items *[]T, header string) (*page[T], bool) {
+	s.mu.Lock()
+	snapshot := append([]T(nil), *items...)
+	s.mu.Unlock()
+	p, err := paginate(s, r, snapshot, r.Header.Get(header))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, err.Error())
+		return nil, false
+	}
+	return p, true
+}
@@ -0,0 +1,387 @@
+package vonetest
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mpkondrashin/vone"
+	_ "modernc.org/sqlite"
+)
+
+func TestParseFilter(t *testing.T) {
+	item := map[string]any{
+		"riskScore": float64(70),
+		"os":        "Windows",
+		"ip":        []any{"10.0.0.1", "10.0.0.2"},
+		"eppAgent":  map[string]any{"status": "on"},
+	}
+	testCases := []struct {
+		filter   string
+		expected bool
+	}{
+		{"", true},
+		{"riskScore gt 50", true},
+		{"riskScore le 50", false},
+		{"os eq 'windows'", true},
+		{"not (os eq 'Windows')", false},
+		{"(os eq 'Linux') or (riskScore ge 70)", true},
+		{"os eq 'Windows' and riskScore lt 70", false},
+		{"ip eq '10.0.0.2'", true},
+		{"ip ne '10.0.0.2'", false},
+		{"eppAgent.status eq 'on'", true},
+		{"missing eq 'x'", false},
+		{"os:Windows", true},
+		{"os:'Linux'", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			expr, err := parseFilter(tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := expr.match(item); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseFilterError(t *testing.T) {
+	for _, filter := range []string{"os eq", "(os eq 'a'", "os like 'a'", "os eq 'a"} {
+		if _, err := parseFilter(filter); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", filter, err)
+		}
+	}
+}
+
+func TestServerSandboxFile(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	v1 := s.NewVOne()
+	ctx := context.Background()
+
+	content := "malicious content"
+	sum := sha1.Sum([]byte(content))
+	expectedSHA1 := hex.EncodeToString(sum[:])
+	submit := v1.SandboxSubmitFile()
+	if err := submit.SetReader(ctx, strings.NewReader(content), "sample.exe"); err != nil {
+		t.Fatal(err)
+	}
+	response, headers, err := submit.Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Digest.SHA1 != expectedSHA1 {
+		t.Errorf("expected SHA1 %s, got %s", expectedSHA1, response.Digest.SHA1)
+	}
+	if headers.SubmissionRemainingCount != DefaultDailyReserve-1 {
+		t.Errorf("expected remaining count %d, got %d", DefaultDailyReserve-1, headers.SubmissionRemainingCount)
+	}
+	if !strings.HasSuffix(headers.OperationLocation, "/v3.0/sandbox/tasks/"+response.ID) {
+		t.Errorf("wrong Operation-Location: %s", headers.OperationLocation)
+	}
+
+	status, err := v1.SandboxSubmissionStatus(response.ID).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != vone.StatusRunning {
+		t.Errorf("expected running, got %v", status.Status)
+	}
+	if _, err := v1.SandboxAnalysisResults(response.ID).Do(ctx); err == nil {
+		t.Errorf("expected error for unfinished analysis")
+	}
+	status, err = v1.SandboxSubmissionStatus(response.ID).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != vone.StatusSucceeded {
+		t.Errorf("expected succeeded, got %v", status.Status)
+	}
+	result, err := v1.SandboxAnalysisResults(response.ID).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RiskLevel != vone.RiskLevelNoRisk || result.Digest.SHA1 != expectedSHA1 {
+		t.Errorf("unexpected result: %v", result)
+	}
+	report, err := v1.SandboxDownloadResults(response.ID).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(report)
+	report.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "%PDF") {
+		t.Errorf("unexpected report: %s", data)
+	}
+}
+
+func TestServerSandboxVerdict(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AnalysisPolls = 0
+	v1 := s.NewVOne()
+	ctx := context.Background()
+
+	url := "http://malicious.example"
+	s.SetURLVerdict(url, Verdict{
+		RiskLevel:      vone.RiskLevelHigh,
+		DetectionNames: []string{"Phishing"},
+		SuspiciousObjects: []vone.SandboxSuspiciousObject{
+			{RiskLevel: vone.RiskLevelHigh, Domain: "malicious.example"},
+		},
+	})
+	response, _, err := v1.SandboxSubmitURLs().AddURL(url).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response) != 1 || response[0].Status != 202 {
+		t.Fatalf("unexpected response: %v", response)
+	}
+	id := response[0].Body.ID
+	if _, err := v1.SandboxSubmissionStatus(id).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	result, err := v1.SandboxAnalysisResults(id).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RiskLevel != vone.RiskLevelHigh {
+		t.Errorf("expected high risk, got %v", result.RiskLevel)
+	}
+	so, err := v1.SandboxSuspiciousObjects(id).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(so.Items) != 1 || so.Items[0].Domain != "malicious.example" {
+		t.Errorf("unexpected suspicious objects: %v", so.Items)
+	}
+	filter := fmt.Sprintf("id eq '%s'", id)
+	count := 0
+	for item, err := range v1.SandboxListAnalysisResults().Filter(filter).Paginator().Range(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.ID != id {
+			t.Errorf("unexpected ID: %s", item.ID)
+		}
+		count++
+	}
+	if count != 1 {
+		t.Errorf("expected 1 result, got %d", count)
+	}
+}
+
+func TestServerSubmitTooManyURLs(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	v1 := s.NewVOne()
+	submit := v1.SandboxSubmitURLs()
+	for i := 0; i <= MaxURLsPerRequest; i++ {
+		submit.AddURL(fmt.Sprintf("http://example.com/%d", i))
+	}
+	_, _, err := submit.Do(context.Background())
+	var httpErr *vone.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Status != 400 {
+		t.Errorf("expected http 400, got %v", err)
+	}
+}
+
+func TestServerWorkbenchPagination(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.PageSize = 3
+	v1 := s.NewVOne()
+	for i := 0; i < 10; i++ {
+		severity := "low"
+		if i%2 == 0 {
+			severity = "high"
+		}
+		s.AddAlerts(vone.WorkbenchAlert{
+			ID:       fmt.Sprintf("WB-%d", i),
+			Severity: severity,
+			Status:   "Open",
+		})
+	}
+	count := 0
+	for alert, err := range v1.WorkbenchListAlerts().Filter("severity eq 'high'").Paginator().Range(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if alert.Severity != "high" {
+			t.Errorf("unexpected severity: %s", alert.Severity)
+		}
+		count++
+	}
+	if count != 5 {
+		t.Errorf("expected 5 alerts, got %d", count)
+	}
+	if actual := s.RequestCount("/v3.0/workbench/alerts"); actual != 2 {
+		t.Errorf("expected 2 requests, got %d", actual)
+	}
+	err := v1.WorkbenchModifyStatus("WB-1").Status(vone.AlertStatusClosed, vone.InvestigationResultFalse_Positive).Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	alert, err := v1.WorkbenchAlertDetails("WB-1").Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alert.Status != "Closed" {
+		t.Errorf("expected Closed, got %s", alert.Status)
+	}
+}
+
+func TestServerEndpointsPagination(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.PageSize = 2
+	v1 := s.NewVOne()
+	for i := 0; i < 5; i++ {
+		s.AddEndpoints(vone.EndpointListItem{
+			AgentGUID:    fmt.Sprintf("guid-%d", i),
+			EndpointName: fmt.Sprintf("host-%d", i),
+		})
+	}
+	count := 0
+	for _, err := range v1.EndPointList().Paginator().Range(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != 5 {
+		t.Errorf("expected 5 endpoints, got %d", count)
+	}
+}
+
+func TestServerRateLimitFault(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.PageSize = 1
+	v1 := s.NewVOne()
+	s.AddOATDetections(
+		vone.ObservedAttackTechniquesEventsItem{UUID: "1"},
+		vone.ObservedAttackTechniquesEventsItem{UUID: "2"},
+	)
+	s.InjectFault(Fault{Path: "/v3.0/oat", Status: 429, Count: 2})
+	var uuids []string
+	for item, err := range v1.GetOATEvents().Paginator().Range(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		uuids = append(uuids, item.UUID)
+	}
+	if strings.Join(uuids, ",") != "1,2" {
+		t.Errorf("unexpected items: %v", uuids)
+	}
+	if actual := s.RequestCount("/v3.0/oat"); actual != 4 {
+		t.Errorf("expected 4 requests, got %d", actual)
+	}
+}
+
+func TestServerServerErrorFault(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	v1 := s.NewVOne()
+	s.InjectFault(Fault{Status: 503})
+	_, err := v1.SandboxDailyReserve().Do(context.Background())
+	var httpErr *vone.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Status != 503 {
+		t.Fatalf("expected http 503, got %v", err)
+	}
+	reserve, err := v1.SandboxDailyReserve().Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reserve.SubmissionReserveCount != DefaultDailyReserve {
+		t.Errorf("unexpected reserve: %d", reserve.SubmissionReserveCount)
+	}
+}
+
+func TestServerInvalidToken(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	v1 := vone.NewVOne(s.Domain(), "wrong")
+	v1.AddTransportModifier(s.TransportModifier())
+	_, err := v1.HighRiskDevices().Do(context.Background())
+	var vOneErr vone.Error
+	if !errors.As(err, &vOneErr) || vOneErr.Code != vone.ErrorCodeInvalidCredentials {
+		t.Errorf("expected InvalidCredentials, got %v", err)
+	}
+}
+
+func TestServerRateLimitHeaders(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.RateLimit = 3
+	s.RateLimitWindow = 1
+	v1 := s.NewVOne()
+	limiter := vone.NewHeaderRateLimiter(nil)
+	v1.SetRateLimiter(limiter)
+	for i := 0; i < 7; i++ {
+		if _, err := v1.SandboxDailyReserve().Do(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.Throttled() != 0 {
+		t.Errorf("expected no throttled requests, got %d", s.Throttled())
+	}
+	stats := limiter.Stats()["sandbox"]
+	if stats.Requests != 7 || stats.Waits == 0 || stats.Throttles != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestServerSharedRateLimit(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.RateLimit = 3
+	s.RateLimitWindow = 1
+	dbPath := filepath.Join(t.TempDir(), "limits.sqlite3")
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		limiter, err := vone.NewSharedRateLimiter(db, dbPath, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v1 := s.NewVOne()
+		v1.SetRateLimiter(limiter)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 4; j++ {
+				if _, err := v1.SandboxDailyReserve().Do(context.Background()); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if s.Throttled() != 0 {
+		t.Errorf("expected no throttled requests, got %d", s.Throttled())
+	}
+}
@@ -0,0 +1,95 @@
+/*
+	Trend Micro Vision One API SDK
+	(c) 2026 by Mikhail Kondrashin (mkondrashin@gmail.com)
+
+	Vision One API emulator
+
+	workbench.go - workbench API emulation
+*/
+
+package vonetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mpkondrashin/vone"
+)
+
+// AddAlerts - seed workbench alerts
+func (s *Server) AddAlerts(alerts ...vone.WorkbenchAlert) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts = append(s.alerts, alerts...)
+}
+
+// Alert - return current state of workbench alert
+func (s *Server) Alert(id string) (vone.WorkbenchAlert, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, alert := range s.alerts {
+		if alert.ID == id {
+			return alert, true
+		}
+	}
+	return vone.WorkbenchAlert{}, false
+}
+
+func (s *Server) workbenchRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /v3.0/workbench/alerts", s.listAlerts)
+	mux.HandleFunc("GET /v3.0/workbench/alerts/{id}", s.alertDetails)
+	mux.HandleFunc("PATCH /v3.0/workbench/alerts/{id}", s.modifyAlert)
+}
+
+func (s *Server) listAlerts(w http.ResponseWriter, r *http.Request) {
+	p, ok := paginateHeader(s, w, r, &s.alerts, "TMV1-Filter")
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, vone.WorkbenchAlertsResponse{
+		TotalCount: p.TotalCount,
+		Count:      len(p.Items),
+		Items:      p.Items,
+		NextLink:   p.NextLink,
+	})
+}
+
+// alertIndex - should be called with mu locked
+func (s *Server) alertIndex(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id := r.PathValue("id")
+	for i := range s.alerts {
+		if s.alerts[i].ID == id {
+			return i, true
+		}
+	}
+	writeError(w, http.StatusNotFound, vone.ErrorCodeNotFound, fmt.Sprintf("Alert %s not found", id))
+	return 0, false
+}
+
+func (s *Server) alertDetails(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.alertIndex(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.alerts[i])
+}
+
+func (s *Server) modifyAlert(w http.ResponseWriter, r *http.Request) {
+	var request vone.WorkbenchModifyStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, vone.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.alertIndex(w, r)
+	if !ok {
+		return
+	}
+	s.alerts[i].Status = request.Status.String()
+	s.alerts[i].InvestigationResult = request.InvestigationResult.String()
+	w.WriteHeader(http.StatusNoContent)
+}
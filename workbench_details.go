@@ -52,6 +52,10 @@ func (f *workbenchDetailsRequest) url() string {
 	return fmt.Sprintf("/v3.0/workbench/alerts/%s", f.id)
 }
 
+func (f *workbenchDetailsRequest) operation() string {
+	return "WorkbenchAlertDetails"
+}
+
 func (f *workbenchDetailsRequest) responseStruct() any {
 	return &f.response
 }
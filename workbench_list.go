@@ -178,6 +178,10 @@ func (*workbenchListRequest) url() string {
 	return "/v3.0/workbench/alerts"
 }
 
+func (*workbenchListRequest) operation() string {
+	return "WorkbenchListAlerts"
+}
+
 func (f *workbenchListRequest) uri() string {
 	return f.response.NextLink
 }
@@ -194,6 +198,10 @@ func (f *workbenchListRequest) resetPagination() {
 	f.response.NextLink = ""
 }
 
+func (f *workbenchListRequest) setNextLink(link string) {
+	f.response.NextLink = link
+}
+
 // Next - get next page of results
 func (f *workbenchListRequest) Next(ctx context.Context) (*WorkbenchAlertsResponse, error) {
 	if f.response.NextLink == "" {
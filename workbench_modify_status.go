@@ -77,6 +77,10 @@ func (f *workbenchModifyStatusRequest) url() string {
 	return fmt.Sprintf("/v3.0/workbench/alerts/%s", f.id)
 }
 
+func (f *workbenchModifyStatusRequest) operation() string {
+	return "WorkbenchModifyStatus"
+}
+
 func (f *workbenchModifyStatusRequest) requestBody() io.Reader {
 	data, err := json.Marshal(f.request)
 	if err != nil {